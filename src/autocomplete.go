@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/webapi"
+)
+
+// noIdentity is the empty identity Azure DevOps uses to clear auto-complete
+const noIdentity = "00000000-0000-0000-0000-000000000000"
+
+// SetAutoComplete makes the pull request complete itself once its policies pass,
+// on behalf of the given user
+func (s *Session) SetAutoComplete(pr PullRequestInfo, userID string, options *git.GitPullRequestCompletionOptions) error {
+	_, err := s.gitClient.UpdatePullRequest(s.ctx, git.UpdatePullRequestArgs{
+		GitPullRequestToUpdate: &git.GitPullRequest{
+			AutoCompleteSetBy: &webapi.IdentityRef{Id: &userID},
+			CompletionOptions: options,
+		},
+		RepositoryId:  &pr.repositoryID,
+		PullRequestId: &pr.id,
+		Project:       &s.project,
+	})
+	return err
+}
+
+// CancelAutoComplete turns auto-complete off for the pull request
+func (s *Session) CancelAutoComplete(pr PullRequestInfo) error {
+	id := noIdentity
+	_, err := s.gitClient.UpdatePullRequest(s.ctx, git.UpdatePullRequestArgs{
+		GitPullRequestToUpdate: &git.GitPullRequest{
+			AutoCompleteSetBy: &webapi.IdentityRef{Id: &id},
+		},
+		RepositoryId:  &pr.repositoryID,
+		PullRequestId: &pr.id,
+		Project:       &s.project,
+	})
+	return err
+}
+
+// openAutoCompleteForm shows the auto-complete options for the selected PR, or
+// asks to cancel auto-complete when it is already set
+func (m tuiModel) openAutoCompleteForm() (tuiModel, tea.Cmd) {
+	pr, ok := m.selectedPR()
+	if !ok {
+		return m, nil
+	}
+	if pr.autoCompleteSetByID != "" {
+		// The author and whoever set auto-complete may cancel it
+		if pr.creatorID != m.userID && pr.autoCompleteSetByID != m.userID {
+			return m, func() tea.Msg {
+				return statusMsg{err: fmt.Errorf("only the author of !%d or %s, who set auto-complete, can cancel it", pr.id, pr.autoCompleteSetBy)}
+			}
+		}
+		return m.openForm(formState{
+			kind:   formCancelAutoComplete,
+			title:  fmt.Sprintf("Cancel auto-complete of !%d %s, set by %s?", pr.id, pr.title, pr.autoCompleteSetBy),
+			submit: "Cancel auto-complete",
+			pr:     pr,
+		}), nil
+	}
+	if pr.closed() {
+		return m, func() tea.Msg { return statusMsg{err: m.completeBlockers(pr)} }
+	}
+	if pr.IsDraft {
+		return m, func() tea.Msg {
+			return statusMsg{err: fmt.Errorf("!%d is a draft and must be published first", pr.id)}
+		}
+	}
+	return m.openForm(formState{
+		kind:   formAutoComplete,
+		title:  fmt.Sprintf("Auto-complete !%d %s when policies pass", pr.id, pr.title),
+		submit: "Set auto-complete",
+		pr:     pr,
+		fields: completionFields(pr),
+	}), nil
+}
+
+// autoCompleteCmd sets or cancels auto-complete for the PR of the open form
+func (m tuiModel) autoCompleteCmd() tea.Cmd {
+	session, pr, userID, kind := m.session, m.form.pr, m.userID, m.form.kind
+	var options *git.GitPullRequestCompletionOptions
+	if kind == formAutoComplete {
+		options = completionOptions(m.form.fields)
+	}
+	name := m.userName()
+	return func() tea.Msg {
+		if session == nil {
+			return formDoneMsg{kind: kind, pr: pr, err: errors.New("not connected")}
+		}
+		if kind == formCancelAutoComplete {
+			if err := session.CancelAutoComplete(pr); err != nil {
+				return formDoneMsg{kind: kind, pr: pr, err: err}
+			}
+			pr.autoCompleteSetBy, pr.autoCompleteSetByID = "", ""
+			return formDoneMsg{kind: kind, pr: pr, text: fmt.Sprintf("Cancelled auto-complete of !%d", pr.id)}
+		}
+		if err := session.SetAutoComplete(pr, userID, options); err != nil {
+			return formDoneMsg{kind: kind, pr: pr, err: err}
+		}
+		pr.autoCompleteSetBy, pr.autoCompleteSetByID = name, userID
+		return formDoneMsg{kind: kind, pr: pr, text: fmt.Sprintf("!%d will complete when its policies pass", pr.id)}
+	}
+}
+
+// userName returns the display name of the current user as seen on the loaded PRs
+func (m tuiModel) userName() string {
+	for _, pr := range m.prs {
+		if pr.creatorID == m.userID {
+			return pr.creator
+		}
+		for _, rev := range pr.reviewers {
+			if rev.id == m.userID {
+				return rev.displayName
+			}
+		}
+	}
+	return "you"
+}
+
+// replacePR swaps in an updated copy of a PR
+func (m *tuiModel) replacePR(updated PullRequestInfo) {
+	for i, pr := range m.prs {
+		if pr.id == updated.id {
+			m.prs[i] = updated
+		}
+	}
+	// The update can take the PR out of the filtered list
+	m.selected = max(0, min(m.selected, len(m.filteredPRs())-1))
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCancelAutoCompleteAllowedFor(t *testing.T) {
+	tests := []struct {
+		name    string
+		creator string
+		setBy   string
+		allowed bool
+	}{
+		{name: "set by me on someone else's PR", creator: "ada", setBy: "me", allowed: true},
+		{name: "set by someone else on my PR", creator: "me", setBy: "ada", allowed: true},
+		{name: "someone else's PR and setting", creator: "ada", setBy: "bob", allowed: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := reviewedByMe(1, "First")
+			pr.creatorID, pr.autoCompleteSetByID, pr.autoCompleteSetBy = tt.creator, tt.setBy, "Someone"
+			m := testModel(pr)
+			m.showMine = true
+			m, cmd := m.openAutoCompleteForm()
+			if allowed := m.view == viewForm && m.form.kind == formCancelAutoComplete; allowed != tt.allowed {
+				t.Fatalf("cancel form opened = %v, want %v", allowed, tt.allowed)
+			}
+			if !tt.allowed {
+				msg := cmd().(statusMsg)
+				if msg.err == nil || !strings.Contains(msg.err.Error(), "Someone, who set auto-complete") {
+					t.Fatalf("status = %v, want who may cancel", msg.err)
+				}
+			}
+		})
+	}
+}
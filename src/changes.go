@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aymanbagabas/go-udiff"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+)
+
+// maxDiffBytes caps the size of a file version we are willing to diff
+const maxDiffBytes = 1 << 20
+
+// fileChange is a file touched by a pull request
+type fileChange struct {
+	path             string
+	originalPath     string // set for renames
+	changeType       string // add, edit, delete or rename
+	objectID         string // blob after the change, empty for deletes
+	originalObjectID string // blob before the change, empty for adds
+}
+
+// diffLine is a single line of a diff with its line numbers on each side (0 when absent)
+type diffLine struct {
+	kind  udiff.OpKind
+	text  string
+	oldNo int
+	newNo int
+}
+
+// diffHunk is a run of changed lines with surrounding context
+type diffHunk struct {
+	oldStart, oldCount int
+	newStart, newCount int
+	lines              []diffLine
+}
+
+// header renders the hunk header in unified diff notation
+func (h diffHunk) header() string {
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.oldStart, h.oldCount, h.newStart, h.newCount)
+}
+
+// fileDiff is the line diff of one changed file
+type fileDiff struct {
+	change   fileChange
+	hunks    []diffHunk
+	binary   bool
+	tooLarge bool
+	// Tokenised source lines of both versions, used for syntax colouring
+	oldCode [][]codeToken
+	newCode [][]codeToken
+}
+
+// LatestIterationID returns the id of the most recent push to the pull request
+func (s *Session) LatestIterationID(pr PullRequestInfo) (int, error) {
+	iterations, err := s.gitClient.GetPullRequestIterations(s.ctx, git.GetPullRequestIterationsArgs{
+		RepositoryId:  &pr.repositoryID,
+		PullRequestId: &pr.id,
+		Project:       &s.project,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if iterations == nil || len(*iterations) == 0 {
+		return 0, fmt.Errorf("pull request %d has no iterations", pr.id)
+	}
+	last := (*iterations)[len(*iterations)-1]
+	return derefInt(last.Id), nil
+}
+
+// ListPullRequestChanges returns the files changed in an iteration compared to
+// another iteration, where compareTo 0 means the common base of the branches
+func (s *Session) ListPullRequestChanges(pr PullRequestInfo, iterationID, compareTo int) ([]fileChange, error) {
+	var files []fileChange
+	top, skip := 2000, 0
+	for {
+		changes, err := s.gitClient.GetPullRequestIterationChanges(s.ctx, git.GetPullRequestIterationChangesArgs{
+			RepositoryId:  &pr.repositoryID,
+			PullRequestId: &pr.id,
+			IterationId:   &iterationID,
+			Project:       &s.project,
+			Top:           &top,
+			Skip:          &skip,
+			CompareTo:     &compareTo,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if changes == nil || changes.ChangeEntries == nil {
+			return files, nil
+		}
+		for _, entry := range *changes.ChangeEntries {
+			if change, ok := createFileChange(entry); ok {
+				files = append(files, change)
+			}
+		}
+		if derefInt(changes.NextSkip) == 0 {
+			return files, nil
+		}
+		skip = derefInt(changes.NextSkip)
+	}
+}
+
+// createFileChange converts an iteration change entry, skipping folders
+func createFileChange(entry git.GitPullRequestChange) (fileChange, bool) {
+	// The item is returned as a loosely typed object
+	item, _ := entry.Item.(map[string]interface{})
+	str := func(key string) string {
+		v, _ := item[key].(string)
+		return v
+	}
+	if isFolder, _ := item["isFolder"].(bool); isFolder {
+		return fileChange{}, false
+	}
+	change := fileChange{
+		path:             str("path"),
+		originalPath:     derefString(entry.OriginalPath),
+		objectID:         str("objectId"),
+		originalObjectID: str("originalObjectId"),
+	}
+	changeType := ""
+	if entry.ChangeType != nil {
+		changeType = string(*entry.ChangeType)
+	}
+	switch {
+	case strings.Contains(changeType, "delete"):
+		change.changeType = "delete"
+		// Deleted items only carry the id of the removed blob
+		if change.originalObjectID == "" {
+			change.originalObjectID = change.objectID
+		}
+		change.objectID = ""
+	case strings.Contains(changeType, "add"):
+		change.changeType = "add"
+		change.originalObjectID = ""
+	case strings.Contains(changeType, "rename"):
+		change.changeType = "rename"
+	default:
+		change.changeType = "edit"
+	}
+	if change.path == "" {
+		change.path = change.originalPath
+	}
+	return change, true
+}
+
+// FileDiff downloads both versions of a changed file and diffs them
+func (s *Session) FileDiff(pr PullRequestInfo, change fileChange) (fileDiff, error) {
+	oldText, err := s.blobContent(pr, change.originalObjectID)
+	if err != nil {
+		return fileDiff{}, err
+	}
+	newText, err := s.blobContent(pr, change.objectID)
+	if err != nil {
+		return fileDiff{}, err
+	}
+	return computeFileDiff(change, oldText, newText), nil
+}
+
+// blobContent returns the content of a blob, or an empty slice when id is empty
+func (s *Session) blobContent(pr PullRequestInfo, id string) ([]byte, error) {
+	if id == "" {
+		return nil, nil
+	}
+	body, err := s.gitClient.GetBlobContent(s.ctx, git.GetBlobContentArgs{
+		RepositoryId: &pr.repositoryID,
+		Sha1:         &id,
+		Project:      &s.project,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	// Read one byte past the limit so oversized files can be detected
+	return io.ReadAll(io.LimitReader(body, maxDiffBytes+1))
+}
+
+// computeFileDiff builds the hunks of a file diff with three lines of context
+func computeFileDiff(change fileChange, oldText, newText []byte) fileDiff {
+	d := fileDiff{change: change}
+	if len(oldText) > maxDiffBytes || len(newText) > maxDiffBytes {
+		d.tooLarge = true
+		return d
+	}
+	if isBinary(oldText) || isBinary(newText) {
+		d.binary = true
+		return d
+	}
+	before, after := string(oldText), string(newText)
+	edits := udiff.Strings(before, after)
+	unified, err := udiff.ToUnifiedDiff(change.originalPath, change.path, before, edits, udiff.DefaultContextLines)
+	if err != nil {
+		return d
+	}
+	// Number new lines from how far the new file has drifted from the old one
+	offset := 0
+	for _, h := range unified.Hunks {
+		hunk := diffHunk{oldStart: h.FromLine, newStart: h.FromLine + offset}
+		oldNo, newNo := hunk.oldStart, hunk.newStart
+		for _, l := range h.Lines {
+			line := diffLine{kind: l.Kind, text: strings.TrimRight(l.Content, "\r\n")}
+			switch l.Kind {
+			case udiff.Delete:
+				line.oldNo = oldNo
+				oldNo++
+				hunk.oldCount++
+			case udiff.Insert:
+				line.newNo = newNo
+				newNo++
+				hunk.newCount++
+			default:
+				line.oldNo, line.newNo = oldNo, newNo
+				oldNo++
+				newNo++
+				hunk.oldCount++
+				hunk.newCount++
+			}
+			hunk.lines = append(hunk.lines, line)
+		}
+		offset += hunk.newCount - hunk.oldCount
+		d.hunks = append(d.hunks, hunk)
+	}
+	d.oldCode = tokenizeCode(change.path, before)
+	d.newCode = tokenizeCode(change.path, after)
+	return d
+}
+
+// isBinary uses the same heuristic as git: a NUL byte near the start means binary
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), 8000)], 0) >= 0
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+)
+
+func TestCreateFileChange(t *testing.T) {
+	entry := func(changeType string, item map[string]interface{}, originalPath string) git.GitPullRequestChange {
+		kind := git.VersionControlChangeType(changeType)
+		e := git.GitPullRequestChange{ChangeType: &kind, Item: item}
+		if originalPath != "" {
+			e.OriginalPath = &originalPath
+		}
+		return e
+	}
+	file := map[string]interface{}{"path": "/src/a.go", "objectId": "new", "originalObjectId": "old"}
+	tests := []struct {
+		name  string
+		entry git.GitPullRequestChange
+		want  fileChange
+		ok    bool
+	}{
+		{name: "edit", entry: entry("edit", file, ""), ok: true,
+			want: fileChange{path: "/src/a.go", changeType: "edit", objectID: "new", originalObjectID: "old"}},
+		{name: "add", entry: entry("add", file, ""), ok: true,
+			want: fileChange{path: "/src/a.go", changeType: "add", objectID: "new"}},
+		{name: "delete keeps the removed blob", entry: entry("delete", map[string]interface{}{"path": "/src/a.go", "objectId": "gone"}, ""), ok: true,
+			want: fileChange{path: "/src/a.go", changeType: "delete", originalObjectID: "gone"}},
+		{name: "delete with both ids", entry: entry("delete", file, ""), ok: true,
+			want: fileChange{path: "/src/a.go", changeType: "delete", originalObjectID: "old"}},
+		{name: "rename", entry: entry("rename", file, "/src/old.go"), ok: true,
+			want: fileChange{path: "/src/a.go", originalPath: "/src/old.go", changeType: "rename", objectID: "new", originalObjectID: "old"}},
+		{name: "edited rename", entry: entry("edit, rename", file, "/src/old.go"), ok: true,
+			want: fileChange{path: "/src/a.go", originalPath: "/src/old.go", changeType: "rename", objectID: "new", originalObjectID: "old"}},
+		{name: "path from the original", entry: entry("delete, sourceRename", map[string]interface{}{"objectId": "gone"}, "/src/old.go"), ok: true,
+			want: fileChange{path: "/src/old.go", originalPath: "/src/old.go", changeType: "delete", originalObjectID: "gone"}},
+		{name: "folder", entry: entry("add", map[string]interface{}{"path": "/src", "isFolder": true}, ""), ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := createFileChange(tt.entry)
+			if ok != tt.ok || got != tt.want {
+				t.Fatalf("createFileChange() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+// diffRows renders the hunks of a diff as headers and "-old +new text" rows
+func diffRows(d fileDiff) []string {
+	var rows []string
+	for _, h := range d.hunks {
+		rows = append(rows, h.header())
+		for _, l := range h.lines {
+			rows = append(rows, fmt.Sprintf("-%d +%d %s", l.oldNo, l.newNo, l.text))
+		}
+	}
+	return rows
+}
+
+func TestComputeFileDiff(t *testing.T) {
+	lines := func(from, to int) string {
+		var b bytes.Buffer
+		for i := from; i <= to; i++ {
+			fmt.Fprintln(&b, i)
+		}
+		return b.String()
+	}
+	tests := []struct {
+		name     string
+		old, new string
+		binary   bool
+		tooLarge bool
+		want     []string
+	}{
+		{name: "added file", old: "", new: "x\ny\n", want: []string{"@@ -1,0 +1,2 @@", "-0 +1 x", "-0 +2 y"}},
+		{name: "deleted file", old: "x\ny\n", new: "", want: []string{"@@ -1,2 +1,0 @@", "-1 +0 x", "-2 +0 y"}},
+		{name: "edited line", old: "a\nb\nc\n", new: "a\nB\nc\n", want: []string{"@@ -1,3 +1,3 @@", "-1 +1 a", "-2 +0 b", "-0 +2 B", "-3 +3 c"}},
+		{name: "line numbers drift", old: lines(1, 12), new: lines(0, 11), want: []string{
+			"@@ -1,3 +1,4 @@", "-0 +1 0", "-1 +2 1", "-2 +3 2", "-3 +4 3",
+			"@@ -9,4 +10,3 @@", "-9 +10 9", "-10 +11 10", "-11 +12 11", "-12 +0 12",
+		}},
+		{name: "crlf", old: "a\r\nb\r\n", new: "a\r\nc\r\n", want: []string{"@@ -1,2 +1,2 @@", "-1 +1 a", "-2 +0 b", "-0 +2 c"}},
+		{name: "unchanged", old: "a\n", new: "a\n"},
+		{name: "binary", old: "a\n", new: "a\x00b", binary: true},
+		{name: "too large", old: "", new: string(bytes.Repeat([]byte("a"), maxDiffBytes+1)), tooLarge: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := computeFileDiff(fileChange{path: "f.txt"}, []byte(tt.old), []byte(tt.new))
+			if d.binary != tt.binary || d.tooLarge != tt.tooLarge {
+				t.Fatalf("binary = %v, tooLarge = %v, want %v, %v", d.binary, d.tooLarge, tt.binary, tt.tooLarge)
+			}
+			if rows := diffRows(d); !reflect.DeepEqual(rows, tt.want) {
+				t.Fatalf("diff = %q, want %q", rows, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/policy"
+)
+
+// checkState is the outcome of a policy or status check
+type checkState int
+
+const (
+	checkNone checkState = iota // no checks apply
+	checkPassed
+	checkPending
+	checkFailed
+)
+
+// policyCheck is one branch policy evaluation or status posted to a PR
+type policyCheck struct {
+	name     string
+	state    checkState
+	blocking bool // an optional policy or a status never blocks completion
+}
+
+// prChecks holds the checks of one PR once they have been loaded
+type prChecks struct {
+	items []policyCheck
+	err   error
+}
+
+// overall summarises the checks: any failure wins, then anything still running
+func (c prChecks) overall() checkState {
+	state := checkNone
+	for _, item := range c.items {
+		state = max(state, item.state)
+	}
+	return state
+}
+
+// blocking reports whether a required policy has not passed yet
+func (c prChecks) blocking() bool {
+	for _, item := range c.items {
+		if item.blocking && item.state != checkPassed {
+			return true
+		}
+	}
+	return false
+}
+
+// PullRequestChecks returns the branch policy evaluations and status checks of a PR
+func (s *Session) PullRequestChecks(pr PullRequestInfo) ([]policyCheck, error) {
+	policyClient, err := s.policy()
+	if err != nil {
+		return nil, err
+	}
+	// Policies are evaluated against the PR as a code review artifact
+	artifactID := fmt.Sprintf("vstfs:///CodeReview/CodeReviewId/%s/%d", pr.projectID, pr.id)
+	evaluations, err := policyClient.GetPolicyEvaluations(s.ctx, policy.GetPolicyEvaluationsArgs{
+		Project:    &s.project,
+		ArtifactId: &artifactID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var checks []policyCheck
+	if evaluations != nil {
+		for _, evaluation := range *evaluations {
+			if check, ok := createPolicyCheck(evaluation); ok {
+				checks = append(checks, check)
+			}
+		}
+	}
+	statuses, err := s.gitClient.GetPullRequestStatuses(s.ctx, git.GetPullRequestStatusesArgs{
+		RepositoryId:  &pr.repositoryID,
+		PullRequestId: &pr.id,
+		Project:       &s.project,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if statuses != nil {
+		checks = append(checks, latestStatusChecks(*statuses)...)
+	}
+	return checks, nil
+}
+
+// createPolicyCheck converts a policy evaluation, skipping policies that do not apply
+func createPolicyCheck(evaluation policy.PolicyEvaluationRecord) (policyCheck, bool) {
+	config := evaluation.Configuration
+	if config == nil || !derefBool(config.IsEnabled) || derefBool(config.IsDeleted) {
+		return policyCheck{}, false
+	}
+	check := policyCheck{blocking: derefBool(config.IsBlocking)}
+	if config.Type != nil {
+		check.name = derefString(config.Type.DisplayName)
+	}
+	// Build policies name the pipeline they run in their settings
+	settings, _ := config.Settings.(map[string]interface{})
+	if name, _ := settings["displayName"].(string); name != "" {
+		check.name += ": " + name
+	}
+	status := policy.PolicyEvaluationStatus("")
+	if evaluation.Status != nil {
+		status = *evaluation.Status
+	}
+	switch status {
+	case policy.PolicyEvaluationStatusValues.Approved:
+		check.state = checkPassed
+	case policy.PolicyEvaluationStatusValues.Rejected, policy.PolicyEvaluationStatusValues.Broken:
+		check.state = checkFailed
+	case policy.PolicyEvaluationStatusValues.NotApplicable:
+		return policyCheck{}, false
+	default:
+		check.state = checkPending
+	}
+	return check, true
+}
+
+// latestStatusChecks converts the statuses posted to a PR, keeping only the
+// newest status of each genre and name
+func latestStatusChecks(statuses []git.GitPullRequestStatus) []policyCheck {
+	latest := make(map[string]git.GitPullRequestStatus)
+	for _, status := range statuses {
+		name := ""
+		if status.Context != nil {
+			name = derefString(status.Context.Name)
+			if genre := derefString(status.Context.Genre); genre != "" {
+				name = genre + "/" + name
+			}
+		}
+		if prev, ok := latest[name]; !ok || derefInt(status.Id) > derefInt(prev.Id) {
+			latest[name] = status
+		}
+	}
+	names := make([]string, 0, len(latest))
+	for name := range latest {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var checks []policyCheck
+	for _, name := range names {
+		check := policyCheck{name: name}
+		state := git.GitStatusState("")
+		if latest[name].State != nil {
+			state = *latest[name].State
+		}
+		switch state {
+		case git.GitStatusStateValues.Succeeded:
+			check.state = checkPassed
+		case git.GitStatusStateValues.Failed, git.GitStatusStateValues.Error:
+			check.state = checkFailed
+		case git.GitStatusStateValues.NotApplicable:
+			continue
+		default:
+			check.state = checkPending
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+type checksLoadedMsg struct {
+	prID   int
+	checks []policyCheck
+	err    error
+}
+
+// checksLimit caps how many PRs have their checks fetched at the same time
+var checksLimit = make(chan struct{}, 8)
+
+// loadChecksCmd fetches the checks of every PR, reporting each PR as it completes
+func loadChecksCmd(session *Session, prs []PullRequestInfo) tea.Cmd {
+	if session == nil {
+		return nil
+	}
+	cmds := make([]tea.Cmd, 0, len(prs))
+	for _, pr := range prs {
+		cmds = append(cmds, func() tea.Msg {
+			checksLimit <- struct{}{}
+			defer func() { <-checksLimit }()
+			checks, err := session.PullRequestChecks(pr)
+			return checksLoadedMsg{prID: pr.id, checks: checks, err: err}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// checkIcon renders the compact indicator for a check state
+func checkIcon(state checkState) string {
+	switch state {
+	case checkPassed:
+		return checkPassStyle.Render("✔")
+	case checkFailed:
+		return checkFailStyle.Render("✗")
+	case checkPending:
+		return checkPendingStyle.Render("●")
+	default:
+		return " "
+	}
+}
+
+// checksIndicator renders the list indicator of a PR, or a dot while its checks load
+func (m tuiModel) checksIndicator(prID int) string {
+	checks, ok := m.checks[prID]
+	switch {
+	case !ok:
+		return sepStyle.Render("·")
+	case checks.err != nil:
+		return checkFailStyle.Render("?")
+	}
+	return checkIcon(checks.overall())
+}
+
+// renderChecks renders the breakdown of a PR's checks for the detail pane
+func (m tuiModel) renderChecks(prID, width int) []string {
+	lines := []string{"Checks:"}
+	checks, ok := m.checks[prID]
+	switch {
+	case !ok:
+		return append(lines, sepStyle.Render("Loading..."))
+	case checks.err != nil:
+		return append(lines, errorStyle.Render(truncate(checks.err.Error(), width)))
+	case len(checks.items) == 0:
+		return append(lines, sepStyle.Render("No policies or status checks."))
+	}
+	for _, check := range checks.items {
+		suffix := ""
+		if check.blocking {
+			suffix = " (required)"
+		}
+		line := checkIcon(check.state) + " " + truncate(check.name+suffix, width-2)
+		lines = append(lines, lipgloss.NewStyle().MaxWidth(width).Render(line))
+	}
+	return lines
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+	"golang.org/x/term"
+)
+
+// CopyToClipboard copies text using the OSC 52 terminal escape sequence, which also
+// works over SSH. Not every terminal supports OSC 52, so local sessions also use the
+// system clipboard tool when one is installed.
+func CopyToClipboard(text string) error {
+	sent := false
+	if term.IsTerminal(int(os.Stderr.Fd())) {
+		seq := osc52.New(text)
+		if os.Getenv("TMUX") != "" {
+			seq = seq.Tmux()
+		} else if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+			seq = seq.Screen()
+		}
+		_, err := seq.WriteTo(os.Stderr)
+		sent = err == nil
+	}
+	if sent && os.Getenv("SSH_CONNECTION") != "" {
+		return nil
+	}
+	if err := copyWithSystemTool(text); err != nil && !sent {
+		return err
+	}
+	return nil
+}
+
+// clipboardCommands lists the clipboard tools to try for each platform, in order
+func clipboardCommands() [][]string {
+	switch runtime.GOOS {
+	case "windows":
+		return [][]string{{"clip"}}
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	default:
+		return [][]string{
+			{"wl-copy"},
+			{"xclip", "-selection", "clipboard"},
+			{"xsel", "--clipboard", "--input"},
+		}
+	}
+}
+
+func copyWithSystemTool(text string) error {
+	for _, args := range clipboardCommands() {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd.Run()
+	}
+	return fmt.Errorf("no clipboard tool found")
+}
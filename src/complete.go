@@ -0,0 +1,174 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+)
+
+// mergeStrategies are the merge strategies offered when completing a PR, in the
+// order shown in the form
+var mergeStrategies = []struct {
+	label    string
+	strategy git.GitPullRequestMergeStrategy
+}{
+	{"Merge (no fast-forward)", git.GitPullRequestMergeStrategyValues.NoFastForward},
+	{"Squash commit", git.GitPullRequestMergeStrategyValues.Squash},
+	{"Rebase and fast-forward", git.GitPullRequestMergeStrategyValues.Rebase},
+	{"Semi-linear merge", git.GitPullRequestMergeStrategyValues.RebaseMerge},
+}
+
+// Field order of the completion options, shared by the complete and auto-complete forms
+const (
+	completeStrategy = iota
+	completeDeleteBranch
+	completeTransition
+	completeMessage
+)
+
+// completionFields returns the form fields for the completion options of a PR
+func completionFields(pr PullRequestInfo) []formField {
+	labels := make([]string, len(mergeStrategies))
+	for i, s := range mergeStrategies {
+		labels[i] = s.label
+	}
+	return []formField{
+		completeStrategy:     choiceField("Merge type", labels, 0),
+		completeDeleteBranch: toggleField("Delete source branch", true),
+		completeTransition:   toggleField("Complete work items", true),
+		completeMessage:      textField("Commit message", fmt.Sprintf("Merged PR %d: %s", pr.id, pr.title)),
+	}
+}
+
+// completionOptions builds the API completion options from the form fields
+func completionOptions(fields []formField) *git.GitPullRequestCompletionOptions {
+	strategy := mergeStrategies[fields[completeStrategy].choice].strategy
+	deleteBranch := fields[completeDeleteBranch].on
+	transition := fields[completeTransition].on
+	options := &git.GitPullRequestCompletionOptions{
+		MergeStrategy:       &strategy,
+		DeleteSourceBranch:  &deleteBranch,
+		TransitionWorkItems: &transition,
+	}
+	if message := fields[completeMessage].value(); message != "" {
+		options.MergeCommitMessage = &message
+	}
+	return options
+}
+
+// CompletePullRequest merges the pull request into its target branch. The
+// server may only queue the merge, so the returned PR has the status it reports.
+func (s *Session) CompletePullRequest(pr PullRequestInfo, options *git.GitPullRequestCompletionOptions) (PullRequestInfo, error) {
+	status := git.PullRequestStatusValues.Completed
+	updated, err := s.gitClient.UpdatePullRequest(s.ctx, git.UpdatePullRequestArgs{
+		GitPullRequestToUpdate: &git.GitPullRequest{
+			Status: &status,
+			// The merge only goes ahead if nothing was pushed since this commit
+			LastMergeSourceCommit: &git.GitCommitRef{CommitId: &pr.lastMergeSourceCommit},
+			CompletionOptions:     options,
+		},
+		RepositoryId:  &pr.repositoryID,
+		PullRequestId: &pr.id,
+		Project:       &s.project,
+	})
+	if err != nil || updated == nil {
+		return pr, err
+	}
+	if updated.Status != nil {
+		pr.status = string(*updated.Status)
+	}
+	if updated.ClosedDate != nil {
+		pr.closedDate = updated.ClosedDate.Time
+	}
+	if updated.ClosedBy != nil {
+		pr.closedBy = derefString(updated.ClosedBy.DisplayName)
+	}
+	return pr, nil
+}
+
+// completeBlockers explains why a PR cannot be completed yet, or returns nil
+func (m tuiModel) completeBlockers(pr PullRequestInfo) error {
+	switch pr.status {
+	case "completed":
+		return fmt.Errorf("!%d is already completed", pr.id)
+	case "abandoned":
+		return fmt.Errorf("!%d is abandoned and must be reactivated first", pr.id)
+	}
+	if pr.IsDraft {
+		return fmt.Errorf("!%d is a draft and must be published first", pr.id)
+	}
+	if badge := mergeBadge(pr.mergeStatus); badge != "" {
+		return fmt.Errorf("!%d cannot be merged: %s", pr.id, strings.Trim(badge, "[]"))
+	}
+	checks, ok := m.checks[pr.id]
+	switch {
+	case !ok:
+		return fmt.Errorf("policies of !%d are still loading", pr.id)
+	case checks.err != nil:
+		return fmt.Errorf("could not check the policies of !%d: %w", pr.id, checks.err)
+	}
+	var failing []string
+	for _, check := range checks.items {
+		if check.blocking && check.state != checkPassed {
+			failing = append(failing, check.name)
+		}
+	}
+	if len(failing) > 0 {
+		return fmt.Errorf("required policies have not passed: %s", strings.Join(failing, ", "))
+	}
+	return nil
+}
+
+// openCompleteForm shows the completion form for the selected PR once its policies pass
+func (m tuiModel) openCompleteForm() (tuiModel, tea.Cmd) {
+	pr, ok := m.selectedPR()
+	if !ok {
+		return m, nil
+	}
+	if err := m.completeBlockers(pr); err != nil {
+		return m, func() tea.Msg { return statusMsg{err: err} }
+	}
+	return m.openForm(formState{
+		kind:   formComplete,
+		title:  fmt.Sprintf("Complete !%d %s", pr.id, pr.title),
+		submit: "Complete",
+		pr:     pr,
+		fields: completionFields(pr),
+	}), nil
+}
+
+// completeCmd completes the PR of the open form
+func (m tuiModel) completeCmd() tea.Cmd {
+	session, pr := m.session, m.form.pr
+	options := completionOptions(m.form.fields)
+	// Policies may have changed while the form was open
+	if err := m.completeBlockers(pr); err != nil {
+		return func() tea.Msg { return formDoneMsg{kind: formComplete, pr: pr, err: err} }
+	}
+	return func() tea.Msg {
+		if session == nil {
+			return formDoneMsg{kind: formComplete, pr: pr, err: errors.New("not connected")}
+		}
+		completed, err := session.CompletePullRequest(pr, options)
+		text := fmt.Sprintf("Completed !%d", completed.id)
+		if completed.status == "active" {
+			text = fmt.Sprintf("Completion of !%d is queued", completed.id)
+		}
+		return formDoneMsg{kind: formComplete, pr: completed, text: text, err: err}
+	}
+}
+
+// removePR drops a PR that the status mode no longer lists
+func (m *tuiModel) removePR(id int) {
+	var prs []PullRequestInfo
+	for _, pr := range m.prs {
+		if pr.id != id {
+			prs = append(prs, pr)
+		}
+	}
+	m.prs = prs
+	m.selected = max(0, min(m.selected, len(m.filteredPRs())-1))
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+)
+
+// completeGitClient answers a completion with the given status
+type completeGitClient struct {
+	git.Client
+	status git.PullRequestStatus
+}
+
+func (c completeGitClient) UpdatePullRequest(_ context.Context, args git.UpdatePullRequestArgs) (*git.GitPullRequest, error) {
+	return &git.GitPullRequest{PullRequestId: args.PullRequestId, Status: &c.status}, nil
+}
+
+func TestCompleteTakesStatusFromServer(t *testing.T) {
+	tests := []struct {
+		name   string
+		status git.PullRequestStatus
+		text   string
+		listed bool
+	}{
+		{name: "merged", status: git.PullRequestStatusValues.Completed, text: "Completed !1", listed: false},
+		{name: "queued", status: git.PullRequestStatusValues.Active, text: "Completion of !1 is queued", listed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testModel(reviewedByMe(1, "First"))
+			m.checks[1] = prChecks{}
+			m.session = &Session{ctx: context.Background(), gitClient: completeGitClient{status: tt.status}}
+			m, _ = m.openCompleteForm()
+			if m.view != viewForm {
+				t.Fatalf("complete form not opened: %v", m.status.err)
+			}
+			m.form.busy = true
+			m = update(t, m, m.completeCmd()())
+			if m.status.text != tt.text {
+				t.Errorf("status = %q, want %q", m.status.text, tt.text)
+			}
+			if listed := len(m.prs) == 1; listed != tt.listed {
+				t.Fatalf("listed = %v, want %v", listed, tt.listed)
+			}
+			if tt.listed && m.prs[0].status != "active" {
+				t.Fatalf("status = %q, want active", m.prs[0].status)
+			}
+		})
+	}
+}
+
+func TestCompletionOptions(t *testing.T) {
+	squash := git.GitPullRequestMergeStrategyValues.Squash
+	noFastForward := git.GitPullRequestMergeStrategyValues.NoFastForward
+	on, off := true, false
+	message := "Merged PR 1: First"
+	tests := []struct {
+		name string
+		edit func(fields []formField)
+		want git.GitPullRequestCompletionOptions
+	}{
+		{
+			name: "defaults",
+			edit: func([]formField) {},
+			want: git.GitPullRequestCompletionOptions{MergeStrategy: &noFastForward,
+				DeleteSourceBranch: &on, TransitionWorkItems: &on, MergeCommitMessage: &message},
+		},
+		{
+			name: "squash keeping the branch without a message",
+			edit: func(fields []formField) {
+				fields[completeStrategy].choice = 1
+				fields[completeDeleteBranch].on = false
+				fields[completeTransition].on = false
+				fields[completeMessage] = textField("Commit message", "  ")
+			},
+			want: git.GitPullRequestCompletionOptions{MergeStrategy: &squash,
+				DeleteSourceBranch: &off, TransitionWorkItems: &off},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := completionFields(reviewedByMe(1, "First"))
+			tt.edit(fields)
+			if got := completionOptions(fields); !reflect.DeepEqual(*got, tt.want) {
+				t.Fatalf("options = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const configDirName = "azure-devops-tui"
+const configFileName = "config.json"
+
+// Config holds the optional user settings read from the config file
+type Config struct {
+	// Keys maps an action name (e.g. "toggle_drafts") to the keys bound to it
+	Keys map[string][]string `json:"keys"`
+	// Theme selects a built-in theme (dark, light, high-contrast) or one from Themes
+	Theme string `json:"theme"`
+	// Themes defines additional themes by name
+	Themes map[string]Theme `json:"themes"`
+	// LabelColors sets the chip color of PR labels by name
+	LabelColors map[string]string `json:"label_colors"`
+	// HistoryDays is how far back completed and abandoned PRs are listed, 14 by default
+	HistoryDays int `json:"history_days"`
+	// Staleness sets when waiting PRs are highlighted as getting old
+	Staleness StalenessConfig `json:"staleness"`
+	// Watch sets how often changes are checked for and how they are notified
+	Watch WatchConfig `json:"watch"`
+	// NoColor disables all colors, like setting NO_COLOR
+	NoColor bool `json:"no_color"`
+}
+
+// StalenessConfig holds the age thresholds for waiting PRs
+type StalenessConfig struct {
+	// WarnHours and StaleHours are the ages at which a PR is colored as a warning and as overdue
+	WarnHours  float64 `json:"warn_hours"`
+	StaleHours float64 `json:"stale_hours"`
+	// SkipWeekends leaves Saturdays and Sundays out of ages
+	SkipWeekends bool `json:"skip_weekends"`
+	// Holidays lists further days left out of ages, as YYYY-MM-DD
+	Holidays []string `json:"holidays"`
+}
+
+// WatchConfig holds the settings of `AzurePR watch` and of notifications in the TUI
+type WatchConfig struct {
+	// IntervalSeconds is the time between checks, 120 by default
+	IntervalSeconds int `json:"interval_seconds"`
+	// Notifiers lists where events go: desktop, bell and command. Desktop and bell by default.
+	Notifiers []string `json:"notifiers"`
+	// Command is the shell command run by the command notifier
+	Command string `json:"command"`
+	// TUI also checks for changes and notifies while the TUI runs
+	TUI bool `json:"tui"`
+	// Webhooks are posted every change to the open PRs by `AzurePR watch`
+	Webhooks []WebhookConfig `json:"webhooks"`
+}
+
+// WebhookConfig is one URL events are posted to
+type WebhookConfig struct {
+	URL string `json:"url"`
+	// Preset formats the payload for a chat service: slack or teams. Without a
+	// preset or template the event is posted as plain JSON.
+	Preset string `json:"preset"`
+	// Template is a Go text/template rendering the JSON payload, overriding Preset
+	Template string `json:"template"`
+	// Secret signs every payload with HMAC-SHA256 in the X-AzurePR-Signature header
+	Secret string `json:"secret"`
+	// Events limits which events are posted, all by default
+	Events []string `json:"events"`
+	// Retries is how often a failed post is retried, 3 by default and -1 for never
+	Retries int `json:"retries"`
+}
+
+// defaultWatchInterval is the time between checks unless configured
+const defaultWatchInterval = 2 * time.Minute
+
+// interval returns the time between checks
+func (c WatchConfig) interval() time.Duration {
+	if c.IntervalSeconds > 0 {
+		return time.Duration(c.IntervalSeconds) * time.Second
+	}
+	return defaultWatchInterval
+}
+
+// defaultHistoryDays is how far back closed PRs are listed unless configured
+const defaultHistoryDays = 14
+
+// historyDays returns how many days of completed and abandoned PRs to list
+func (c Config) historyDays() int {
+	if c.HistoryDays > 0 {
+		return c.HistoryDays
+	}
+	return defaultHistoryDays
+}
+
+// ConfigPath returns the location of the config file in the user's config directory
+func ConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, configDirName, configFileName), nil
+}
+
+// LoadConfig reads the config file, returning an empty config if it does not exist
+func LoadConfig() (Config, error) {
+	var cfg Config
+	path, err := ConfigPath()
+	if err != nil {
+		return cfg, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return cfg, nil
+}
@@ -1,27 +1,136 @@
 package main
 
-import "github.com/microsoft/azure-devops-go-api/azuredevops/git"
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+	"ml-winum.dk/azure-devops-tui/src/prdiff"
+)
+
+// createReviewer converts a reviewer with its vote to a PullrequestReviewer
+func createReviewer(rev git.IdentityRefWithVote) PullrequestReviewer {
+	return PullrequestReviewer{
+		id:          derefString(rev.Id),
+		displayName: derefString(rev.DisplayName),
+		isRequired:  derefBool(rev.IsRequired),
+		vote:        derefInt(rev.Vote),
+	}
+}
 
 // createPullRequestInfo converts a GitPullRequest to a PullRequestInfo struct
 func createPullRequestInfo(pr *git.GitPullRequest) PullRequestInfo {
 	var reviewers []PullrequestReviewer
 	if pr.Reviewers != nil {
 		for _, rev := range *pr.Reviewers {
-			reviewer := PullrequestReviewer{
-				id:          derefString(rev.Id),
-				displayName: derefString(rev.DisplayName),
-				isRequired:  derefBool(rev.IsRequired),
-				vote:        derefInt(rev.Vote),
+			reviewers = append(reviewers, createReviewer(rev))
+		}
+	}
+	repository, repositoryID, projectID := "", "", ""
+	if pr.Repository != nil {
+		repository = derefString(pr.Repository.Name)
+		if pr.Repository.Id != nil {
+			repositoryID = pr.Repository.Id.String()
+		}
+		if pr.Repository.Project != nil && pr.Repository.Project.Id != nil {
+			projectID = pr.Repository.Project.Id.String()
+		}
+	}
+	mergeStatus := ""
+	if pr.MergeStatus != nil {
+		mergeStatus = string(*pr.MergeStatus)
+	}
+	lastMergeSourceCommit := ""
+	if pr.LastMergeSourceCommit != nil {
+		lastMergeSourceCommit = derefString(pr.LastMergeSourceCommit.CommitId)
+	}
+	var labels []string
+	if pr.Labels != nil {
+		for _, label := range *pr.Labels {
+			if label.Active == nil || *label.Active {
+				labels = append(labels, derefString(label.Name))
 			}
-			reviewers = append(reviewers, reviewer)
 		}
 	}
+	var createdDate, closedDate time.Time
+	if pr.CreationDate != nil {
+		createdDate = pr.CreationDate.Time
+	}
+	if pr.ClosedDate != nil {
+		closedDate = pr.ClosedDate.Time
+	}
+	closedBy := ""
+	if pr.ClosedBy != nil {
+		closedBy = derefString(pr.ClosedBy.DisplayName)
+	}
+	status := ""
+	if pr.Status != nil {
+		status = string(*pr.Status)
+	}
+	autoCompleteSetBy, autoCompleteSetByID := "", ""
+	if pr.AutoCompleteSetBy != nil {
+		autoCompleteSetBy = derefString(pr.AutoCompleteSetBy.DisplayName)
+		autoCompleteSetByID = derefString(pr.AutoCompleteSetBy.Id)
+	}
 	return PullRequestInfo{
-		id:        derefInt(pr.PullRequestId),
-		title:     derefString(pr.Title),
-		creator:   derefString(pr.CreatedBy.DisplayName),
-		creatorID: derefString(pr.CreatedBy.Id),
-		IsDraft:   derefBool(pr.IsDraft),
-		reviewers: reviewers,
+		id:           derefInt(pr.PullRequestId),
+		title:        derefString(pr.Title),
+		creator:      derefString(pr.CreatedBy.DisplayName),
+		creatorID:    derefString(pr.CreatedBy.Id),
+		IsDraft:      derefBool(pr.IsDraft),
+		reviewers:    reviewers,
+		repository:   repository,
+		repositoryID: repositoryID,
+		projectID:    projectID,
+		url:          pullRequestWebURL(pr),
+		status:       status,
+		description:  derefString(pr.Description),
+		sourceBranch: branchName(derefString(pr.SourceRefName)),
+		targetBranch: branchName(derefString(pr.TargetRefName)),
+		labels:       labels,
+		createdDate:  createdDate,
+		closedDate:   closedDate,
+		closedBy:     closedBy,
+		mergeStatus:  mergeStatus,
+		mergeFailure: derefString(pr.MergeFailureMessage),
+
+		lastMergeSourceCommit: lastMergeSourceCommit,
+		autoCompleteSetBy:     autoCompleteSetBy,
+		autoCompleteSetByID:   autoCompleteSetByID,
+	}
+}
+
+// pullRequestWebURL returns the web page of a pull request, building it from the
+// repository REST url when the API does not include the web url
+func pullRequestWebURL(pr *git.GitPullRequest) string {
+	if pr.Repository == nil {
+		return ""
+	}
+	id := derefInt(pr.PullRequestId)
+	if webURL := derefString(pr.Repository.WebUrl); webURL != "" {
+		return fmt.Sprintf("%s/pullrequest/%d", webURL, id)
+	}
+	// REST urls look like https://dev.azure.com/{org}/{project}/_apis/git/repositories/{id}
+	restURL := derefString(pr.Repository.Url)
+	idx := strings.Index(restURL, "/_apis/")
+	if idx < 0 {
+		return ""
+	}
+	// The REST url has the project escaped already, the repository name is not
+	return fmt.Sprintf("%s/_git/%s/pullrequest/%d", restURL[:idx], url.PathEscape(derefString(pr.Repository.Name)), id)
+}
+
+// diffSnapshot converts pull requests to the snapshot compared by prdiff.Diff
+func diffSnapshot(prs []PullRequestInfo) []prdiff.PR {
+	snapshot := make([]prdiff.PR, len(prs))
+	for i, pr := range prs {
+		reviewers := make([]prdiff.Reviewer, len(pr.reviewers))
+		for j, rev := range pr.reviewers {
+			reviewers[j] = prdiff.Reviewer{ID: rev.id, Name: rev.displayName, Required: rev.isRequired, Vote: rev.vote}
+		}
+		snapshot[i] = prdiff.PR{ID: pr.id, Title: pr.title, Draft: pr.IsDraft, SourceCommit: pr.lastMergeSourceCommit, Reviewers: reviewers}
 	}
+	return snapshot
 }
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+)
+
+func TestPullRequestWebURL(t *testing.T) {
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		name string
+		repo *git.GitRepository
+		want string
+	}{
+		{name: "no repository", want: ""},
+		{
+			name: "web url from the API",
+			repo: &git.GitRepository{Name: str("My Repo"), WebUrl: str("https://dev.azure.com/org/My%20Project/_git/My%20Repo")},
+			want: "https://dev.azure.com/org/My%20Project/_git/My%20Repo/pullrequest/7",
+		},
+		{
+			name: "built from the REST url",
+			repo: &git.GitRepository{Name: str("web"), Url: str("https://dev.azure.com/org/project/_apis/git/repositories/1234")},
+			want: "https://dev.azure.com/org/project/_git/web/pullrequest/7",
+		},
+		{
+			name: "spaces in project and repository",
+			repo: &git.GitRepository{Name: str("My Repo"), Url: str("https://dev.azure.com/org/My%20Project/_apis/git/repositories/1234")},
+			want: "https://dev.azure.com/org/My%20Project/_git/My%20Repo/pullrequest/7",
+		},
+		{
+			name: "reserved characters in the repository",
+			repo: &git.GitRepository{Name: str("a#b?c"), Url: str("https://server/tfs/Collection/Project/_apis/git/repositories/1234")},
+			want: "https://server/tfs/Collection/Project/_git/a%23b%3Fc/pullrequest/7",
+		},
+		{
+			name: "unknown REST url",
+			repo: &git.GitRepository{Name: str("web"), Url: str("https://example.com/web")},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := 7
+			if got := pullRequestWebURL(&git.GitPullRequest{PullRequestId: &id, Repository: tt.repo}); got != tt.want {
+				t.Fatalf("pullRequestWebURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
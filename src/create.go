@@ -0,0 +1,363 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/webapi"
+)
+
+// repoInfo is a repository of the project
+type repoInfo struct {
+	id            string
+	name          string
+	defaultBranch string
+}
+
+// createRequest holds what the user asked for when creating a pull request.
+// Empty fields fall back to the repository defaults.
+type createRequest struct {
+	repository  string
+	source      string
+	target      string
+	title       string
+	description string
+	draft       bool
+	reviewers   []string // names or emails
+	workItems   []int
+}
+
+// ListRepositories returns the repositories of the project, sorted by name
+func (s *Session) ListRepositories() ([]repoInfo, error) {
+	repos, err := s.gitClient.GetRepositories(s.ctx, git.GetRepositoriesArgs{Project: &s.project})
+	if err != nil {
+		return nil, err
+	}
+	var infos []repoInfo
+	if repos != nil {
+		for _, repo := range *repos {
+			if repo.Id == nil {
+				continue
+			}
+			infos = append(infos, repoInfo{
+				id:            repo.Id.String(),
+				name:          derefString(repo.Name),
+				defaultBranch: branchName(derefString(repo.DefaultBranch)),
+			})
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return strings.ToLower(infos[i].name) < strings.ToLower(infos[j].name) })
+	return infos, nil
+}
+
+// ListBranches returns the branch names of a repository
+func (s *Session) ListBranches(repoID string) ([]string, error) {
+	filter := "heads/"
+	var branches []string
+	token := ""
+	for {
+		args := git.GetRefsArgs{RepositoryId: &repoID, Project: &s.project, Filter: &filter}
+		if token != "" {
+			args.ContinuationToken = &token
+		}
+		refs, err := s.gitClient.GetRefs(s.ctx, args)
+		if err != nil {
+			return nil, err
+		}
+		if refs == nil {
+			return branches, nil
+		}
+		for _, ref := range refs.Value {
+			branches = append(branches, branchName(derefString(ref.Name)))
+		}
+		if refs.ContinuationToken == "" {
+			return branches, nil
+		}
+		token = refs.ContinuationToken
+	}
+}
+
+// findRepository picks a repository by name, ignoring case
+func findRepository(repos []repoInfo, name string) (repoInfo, error) {
+	for _, repo := range repos {
+		if strings.EqualFold(repo.name, name) {
+			return repo, nil
+		}
+	}
+	return repoInfo{}, fmt.Errorf("repository %q not found in the project", name)
+}
+
+// CreatePullRequest resolves the request against the project and creates the pull request
+func (s *Session) CreatePullRequest(req createRequest) (PullRequestInfo, error) {
+	repos, err := s.ListRepositories()
+	if err != nil {
+		return PullRequestInfo{}, err
+	}
+	repo, err := findRepository(repos, req.repository)
+	if err != nil {
+		return PullRequestInfo{}, err
+	}
+	if req.target == "" {
+		req.target = repo.defaultBranch
+	}
+	switch {
+	case req.source == "":
+		return PullRequestInfo{}, fmt.Errorf("a source branch is required")
+	case req.title == "":
+		return PullRequestInfo{}, fmt.Errorf("a title is required")
+	case branchName(req.source) == branchName(req.target):
+		return PullRequestInfo{}, fmt.Errorf("source and target are both %s", branchName(req.source))
+	}
+	var reviewers []git.IdentityRefWithVote
+	for _, name := range req.reviewers {
+		reviewer, err := s.ResolveIdentity(name)
+		if err != nil {
+			return PullRequestInfo{}, err
+		}
+		reviewers = append(reviewers, git.IdentityRefWithVote{Id: &reviewer.id})
+	}
+	var workItems []webapi.ResourceRef
+	for _, id := range req.workItems {
+		idStr := strconv.Itoa(id)
+		workItems = append(workItems, webapi.ResourceRef{Id: &idStr})
+	}
+	source, target := refName(req.source), refName(req.target)
+	toCreate := &git.GitPullRequest{
+		SourceRefName: &source,
+		TargetRefName: &target,
+		Title:         &req.title,
+		IsDraft:       &req.draft,
+		Reviewers:     &reviewers,
+		WorkItemRefs:  &workItems,
+	}
+	if req.description != "" {
+		toCreate.Description = &req.description
+	}
+	created, err := s.gitClient.CreatePullRequest(s.ctx, git.CreatePullRequestArgs{
+		GitPullRequestToCreate: toCreate,
+		RepositoryId:           &repo.id,
+		Project:                &s.project,
+	})
+	if err != nil {
+		return PullRequestInfo{}, err
+	}
+	return createPullRequestInfo(created), nil
+}
+
+// refName turns a branch name into a full ref name
+func refName(branch string) string {
+	if strings.HasPrefix(branch, "refs/") {
+		return branch
+	}
+	return "refs/heads/" + branch
+}
+
+// branchName strips refs/heads/ from a ref name
+func branchName(ref string) string {
+	return strings.TrimPrefix(ref, "refs/heads/")
+}
+
+// splitList splits a comma separated list, dropping empty entries
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseWorkItemIDs parses a comma separated list of work item ids, allowing a leading #
+func parseWorkItemIDs(s string) ([]int, error) {
+	var ids []int
+	for _, item := range splitList(s) {
+		id, err := strconv.Atoi(strings.TrimPrefix(item, "#"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid work item id %q", item)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// localCheckout describes the git checkout in the working directory
+type localCheckout struct {
+	repository string // Azure Repos name taken from the origin remote
+	branch     string
+	subject    string // subject of the last commit, a starting point for the title
+}
+
+// azureRemotePattern matches the repository name in Azure Repos remotes:
+// https://dev.azure.com/{org}/{project}/_git/{repo}, https://{org}.visualstudio.com/{project}/_git/{repo}
+// and git@ssh.dev.azure.com:v3/{org}/{project}/{repo}
+var azureRemotePattern = regexp.MustCompile(`(?:/_git/|ssh\.dev\.azure\.com:v3/[^/]+/[^/]+/|vs-ssh\.visualstudio\.com:v3/[^/]+/[^/]+/)([^/?#]+?)(?:\.git)?/?$`)
+
+// repositoryFromRemote returns the repository name of an Azure Repos remote url
+func repositoryFromRemote(remote string) string {
+	match := azureRemotePattern.FindStringSubmatch(strings.TrimSpace(remote))
+	if match == nil {
+		return ""
+	}
+	name, err := url.PathUnescape(match[1])
+	if err != nil {
+		return match[1]
+	}
+	return name
+}
+
+// detectLocalCheckout reads the current branch and origin remote of the local
+// git checkout, leaving fields empty when they cannot be found
+func detectLocalCheckout() localCheckout {
+	var checkout localCheckout
+	if out, err := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output(); err == nil {
+		if branch := strings.TrimSpace(string(out)); branch != "HEAD" {
+			checkout.branch = branch
+		}
+	}
+	if out, err := exec.Command("git", "remote", "get-url", "origin").Output(); err == nil {
+		checkout.repository = repositoryFromRemote(string(out))
+	}
+	if out, err := exec.Command("git", "log", "-1", "--format=%s").Output(); err == nil {
+		checkout.subject = strings.TrimSpace(string(out))
+	}
+	return checkout
+}
+
+// Fields of the create form
+const (
+	createRepository = iota
+	createSource
+	createTarget
+	createTitle
+	createDescription
+	createDraft
+	createReviewers
+	createWorkItems
+)
+
+// checkoutDetectedMsg carries the local git checkout the create form starts from
+type checkoutDetectedMsg struct {
+	checkout localCheckout
+}
+
+// openCreateForm shows the form for a new pull request. It is filled in from
+// the local git checkout once git has answered, which can take a while.
+func (m tuiModel) openCreateForm() (tuiModel, tea.Cmd) {
+	description := textField("Description", "")
+	description.multiline = true
+	m = m.openForm(formState{
+		kind:   formCreate,
+		title:  "New pull request (an empty target is the default branch)",
+		submit: "Create",
+		fields: []formField{
+			textField("Repository", ""),
+			textField("Source", ""),
+			textField("Target", ""),
+			textField("Title", ""),
+			description,
+			toggleField("Draft", false),
+			textField("Reviewers", ""),
+			textField("Work items", ""),
+		},
+		focus: createTitle,
+	})
+	return m, func() tea.Msg { return checkoutDetectedMsg{checkout: detectLocalCheckout()} }
+}
+
+// checkoutDetected fills the create form from the local checkout, leaving
+// fields the user already typed in alone
+func (m tuiModel) checkoutDetected(msg checkoutDetectedMsg) tuiModel {
+	if m.view != viewForm || m.form.kind != formCreate {
+		return m
+	}
+	for field, value := range map[int]string{
+		createRepository: msg.checkout.repository,
+		createSource:     msg.checkout.branch,
+		createTitle:      msg.checkout.subject,
+	} {
+		if m.form.fields[field].value() == "" {
+			m.form.fields[field] = textField(m.form.fields[field].label, value)
+		}
+	}
+	return m
+}
+
+// createCmd creates the pull request described by the open form
+func (m tuiModel) createCmd() tea.Cmd {
+	fields := m.form.fields
+	ids, err := parseWorkItemIDs(fields[createWorkItems].value())
+	if err != nil {
+		return func() tea.Msg { return formDoneMsg{kind: formCreate, err: err} }
+	}
+	req := createRequest{
+		repository:  fields[createRepository].value(),
+		source:      fields[createSource].value(),
+		target:      fields[createTarget].value(),
+		title:       fields[createTitle].value(),
+		description: fields[createDescription].value(),
+		draft:       fields[createDraft].on,
+		reviewers:   splitList(fields[createReviewers].value()),
+		workItems:   ids,
+	}
+	session := m.session
+	return func() tea.Msg {
+		if session == nil {
+			return formDoneMsg{kind: formCreate, err: errors.New("not connected")}
+		}
+		pr, err := session.CreatePullRequest(req)
+		if err != nil {
+			return formDoneMsg{kind: formCreate, err: err}
+		}
+		return formDoneMsg{kind: formCreate, pr: pr, text: fmt.Sprintf("Created !%d %s", pr.id, pr.url)}
+	}
+}
+
+// editorDoneMsg carries the text written in $EDITOR for a form field
+type editorDoneMsg struct {
+	field int
+	text  string
+	err   error
+}
+
+// editorCmd suspends the TUI to edit text in $EDITOR
+func editorCmd(field int, text string) tea.Cmd {
+	file, err := os.CreateTemp("", "azpr-*.md")
+	if err == nil {
+		_, err = file.WriteString(text)
+		file.Close()
+	}
+	if err != nil {
+		return func() tea.Msg { return editorDoneMsg{field: field, err: err} }
+	}
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vim"}
+		if runtime.GOOS == "windows" {
+			editor = []string{"notepad"}
+		}
+	}
+	cmd := exec.Command(editor[0], append(editor[1:], file.Name())...)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		defer os.Remove(file.Name())
+		if err != nil {
+			return editorDoneMsg{field: field, err: fmt.Errorf("editor: %w", err)}
+		}
+		data, err := os.ReadFile(file.Name())
+		if err != nil {
+			return editorDoneMsg{field: field, err: err}
+		}
+		text := strings.TrimSpace(strings.ReplaceAll(string(data), "\r\n", "\n"))
+		return editorDoneMsg{field: field, text: text}
+	})
+}
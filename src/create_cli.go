@@ -0,0 +1,127 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// RunCreate implements `AzurePR create`: flags fill in the pull request and
+// anything left out is asked for, with defaults from the local git checkout
+func RunCreate(session *Session, args []string) error {
+	flags := flag.NewFlagSet("create", flag.ContinueOnError)
+	repository := flags.String("repo", "", "repository name (default: from the origin remote)")
+	source := flags.String("source", "", "source branch (default: the current branch)")
+	target := flags.String("target", "", "target branch (default: the repository's default branch)")
+	title := flags.String("title", "", "title (default: the last commit subject)")
+	description := flags.String("description", "", "description (default: written in $EDITOR)")
+	draft := flags.Bool("draft", false, "create the pull request as a draft")
+	reviewers := flags.String("reviewers", "", "comma separated reviewer names or emails")
+	workItems := flags.String("work-items", "", "comma separated ids of work items to link")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	given := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) { given[f.Name] = true })
+
+	local := detectLocalCheckout()
+	repos, err := session.ListRepositories()
+	if err != nil {
+		return err
+	}
+	if !given["repo"] {
+		names := make([]string, len(repos))
+		for i, repo := range repos {
+			names[i] = repo.name
+		}
+		prompt := &survey.Select{Message: "Repository:", Options: names}
+		if repo, err := findRepository(repos, local.repository); err == nil {
+			prompt.Default = repo.name
+		}
+		if err := survey.AskOne(prompt, repository); err != nil {
+			return err
+		}
+	}
+	repo, err := findRepository(repos, *repository)
+	if err != nil {
+		return err
+	}
+	if !given["source"] || !given["target"] {
+		branches, err := session.ListBranches(repo.id)
+		if err != nil {
+			return err
+		}
+		if !given["source"] {
+			if err := askBranch("Source branch:", branches, local.branch, source); err != nil {
+				return err
+			}
+		}
+		if !given["target"] {
+			if err := askBranch("Target branch:", branches, repo.defaultBranch, target); err != nil {
+				return err
+			}
+		}
+	}
+	if !given["title"] {
+		prompt := &survey.Input{Message: "Title:", Default: local.subject}
+		if err := survey.AskOne(prompt, title, survey.WithValidator(survey.Required)); err != nil {
+			return err
+		}
+	}
+	if !given["description"] {
+		prompt := &survey.Editor{Message: "Description:", FileName: "*.md", HideDefault: true, AppendDefault: true}
+		if err := survey.AskOne(prompt, description); err != nil {
+			return err
+		}
+	}
+	if !given["draft"] {
+		if err := survey.AskOne(&survey.Confirm{Message: "Create as draft?"}, draft); err != nil {
+			return err
+		}
+	}
+	if !given["reviewers"] {
+		prompt := &survey.Input{Message: "Reviewers (comma separated names or emails, optional):"}
+		if err := survey.AskOne(prompt, reviewers); err != nil {
+			return err
+		}
+	}
+	if !given["work-items"] {
+		prompt := &survey.Input{Message: "Work items to link (comma separated ids, optional):"}
+		if err := survey.AskOne(prompt, workItems); err != nil {
+			return err
+		}
+	}
+	ids, err := parseWorkItemIDs(*workItems)
+	if err != nil {
+		return err
+	}
+	pr, err := session.CreatePullRequest(createRequest{
+		repository:  repo.name,
+		source:      *source,
+		target:      *target,
+		title:       strings.TrimSpace(*title),
+		description: strings.TrimSpace(*description),
+		draft:       *draft,
+		reviewers:   splitList(*reviewers),
+		workItems:   ids,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created pull request !%d: %s\n", pr.id, pr.title)
+	fmt.Println(pr.url)
+	return nil
+}
+
+// askBranch lets the user pick a branch, preselecting fallback when it exists
+func askBranch(message string, branches []string, fallback string, answer *string) error {
+	prompt := &survey.Select{Message: message, Options: branches}
+	for _, branch := range branches {
+		if branch == fallback {
+			prompt.Default = fallback
+		}
+	}
+	return survey.AskOne(prompt, answer)
+}
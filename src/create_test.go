@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+func TestCheckoutDetectedFillsEmptyFields(t *testing.T) {
+	m := testModel()
+	m, cmd := m.openCreateForm()
+	if cmd == nil {
+		t.Fatal("the checkout is not detected in the background")
+	}
+	m.form.fields[createTitle] = textField("Title", "Typed while git ran")
+	m = update(t, m, checkoutDetectedMsg{checkout: localCheckout{repository: "web", branch: "feature/x", subject: "Last commit"}})
+	for field, want := range map[int]string{createRepository: "web", createSource: "feature/x", createTitle: "Typed while git ran"} {
+		if got := m.form.fields[field].value(); got != want {
+			t.Errorf("%s = %q, want %q", m.form.fields[field].label, got, want)
+		}
+	}
+
+	// A detection finishing after the form was closed changes nothing
+	m.view = viewPRs
+	m = update(t, m, checkoutDetectedMsg{checkout: localCheckout{repository: "api"}})
+	if got := m.form.fields[createRepository].value(); got != "web" {
+		t.Fatalf("Repository = %q after the form was closed", got)
+	}
+}
+
+func TestRepositoryFromRemote(t *testing.T) {
+	tests := []struct {
+		remote string
+		want   string
+	}{
+		{remote: "https://dev.azure.com/org/project/_git/web", want: "web"},
+		{remote: "https://org@dev.azure.com/org/My%20Project/_git/My%20Repo", want: "My Repo"},
+		{remote: "https://org.visualstudio.com/project/_git/web.git", want: "web"},
+		{remote: "https://org.visualstudio.com/DefaultCollection/project/_git/web/", want: "web"},
+		{remote: "https://tfs.example.com/tfs/Collection/Project/_git/web", want: "web"},
+		{remote: "git@ssh.dev.azure.com:v3/org/project/web", want: "web"},
+		{remote: "ssh://git@ssh.dev.azure.com:v3/org/My%20Project/My%20Repo", want: "My Repo"},
+		{remote: "org@vs-ssh.visualstudio.com:v3/org/project/web", want: "web"},
+		{remote: "  https://dev.azure.com/org/project/_git/web\n", want: "web"},
+		{remote: "https://dev.azure.com/org/project/_git/bad%zz", want: "bad%zz"},
+		{remote: "git@github.com:user/web.git", want: ""},
+		{remote: "https://github.com/user/web", want: ""},
+		{remote: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := repositoryFromRemote(tt.remote); got != tt.want {
+			t.Errorf("repositoryFromRemote(%q) = %q, want %q", tt.remote, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/aymanbagabas/go-udiff"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const tabWidth = 4
+
+// codeToken is a piece of source text with its syntax class
+type codeToken struct {
+	text string
+	kind chroma.TokenType
+}
+
+// tokenizeCode splits source into lines of syntax tokens, picking the lexer from the file name
+func tokenizeCode(path, source string) [][]codeToken {
+	lexer := lexers.Match(path)
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, source)
+	if err != nil {
+		return nil
+	}
+	lines := [][]codeToken{nil}
+	for _, token := range iterator.Tokens() {
+		parts := strings.Split(token.Value, "\n")
+		for i, part := range parts {
+			if i > 0 {
+				lines = append(lines, nil)
+			}
+			if part != "" {
+				last := len(lines) - 1
+				lines[last] = append(lines[last], codeToken{text: part, kind: token.Type})
+			}
+		}
+	}
+	return lines
+}
+
+// syntaxStyle returns the style for a token class
+func syntaxStyle(kind chroma.TokenType) lipgloss.Style {
+	switch {
+	case kind.InCategory(chroma.Keyword):
+		return keywordStyle
+	case kind.InCategory(chroma.Comment):
+		return commentStyle
+	case kind.InSubCategory(chroma.LiteralString):
+		return stringStyle
+	case kind.InSubCategory(chroma.LiteralNumber):
+		return numberStyle
+	default:
+		return lipgloss.NewStyle()
+	}
+}
+
+// renderCode renders one line of tokens clipped and padded to width on the given background
+func renderCode(tokens []codeToken, width int, bg lipgloss.TerminalColor) string {
+	var b strings.Builder
+	used := 0
+	for _, token := range tokens {
+		if used >= width {
+			break
+		}
+		text := []rune(strings.ReplaceAll(token.text, "\t", strings.Repeat(" ", tabWidth)))
+		if len(text) > width-used {
+			text = text[:width-used]
+		}
+		b.WriteString(syntaxStyle(token.kind).Background(bg).Render(string(text)))
+		used += len(text)
+	}
+	if used < width {
+		b.WriteString(lipgloss.NewStyle().Background(bg).Render(strings.Repeat(" ", width-used)))
+	}
+	return b.String()
+}
+
+// codeLine returns the tokens of a diff line, falling back to its plain text
+func (d fileDiff) codeLine(line diffLine) []codeToken {
+	code, no := d.newCode, line.newNo
+	if line.kind == udiff.Delete {
+		code, no = d.oldCode, line.oldNo
+	}
+	if no > 0 && no <= len(code) {
+		return code[no-1]
+	}
+	return []codeToken{{text: line.text}}
+}
+
+// diffRow is one screen row of a diff: a hunk header or a line (unified),
+// or a pair of old and new lines (side by side)
+type diffRow struct {
+	hunk   int
+	header bool
+	left   *diffLine
+	right  *diffLine
+}
+
+// buildDiffRows flattens the hunks into screen rows
+func buildDiffRows(d fileDiff, sideBySide bool) []diffRow {
+	var rows []diffRow
+	for hi, h := range d.hunks {
+		rows = append(rows, diffRow{hunk: hi, header: true})
+		if !sideBySide {
+			for i := range h.lines {
+				rows = append(rows, diffRow{hunk: hi, left: &h.lines[i]})
+			}
+			continue
+		}
+		// Pair up runs of deleted and inserted lines so edits sit next to each other
+		var dels, ins []*diffLine
+		flush := func() {
+			for i := 0; i < max(len(dels), len(ins)); i++ {
+				row := diffRow{hunk: hi}
+				if i < len(dels) {
+					row.left = dels[i]
+				}
+				if i < len(ins) {
+					row.right = ins[i]
+				}
+				rows = append(rows, row)
+			}
+			dels, ins = nil, nil
+		}
+		for i := range h.lines {
+			line := &h.lines[i]
+			switch line.kind {
+			case udiff.Delete:
+				dels = append(dels, line)
+			case udiff.Insert:
+				ins = append(ins, line)
+			default:
+				flush()
+				rows = append(rows, diffRow{hunk: hi, left: line, right: line})
+			}
+		}
+		flush()
+	}
+	return rows
+}
+
+// renderDiffSide renders a line number gutter, change marker and code for one side of a row,
+// numbering the line by its position in the old file when oldSide is set
+func (d fileDiff) renderDiffSide(line *diffLine, width int, oldSide bool) string {
+	if line == nil {
+		return strings.Repeat(" ", width)
+	}
+	var bg lipgloss.TerminalColor = lipgloss.NoColor{}
+	marker := " "
+	switch line.kind {
+	case udiff.Insert:
+		bg, marker = diffAddBg, "+"
+	case udiff.Delete:
+		bg, marker = diffDelBg, "-"
+	}
+	no := line.newNo
+	if oldSide {
+		no = line.oldNo
+	}
+	gutter := sepStyle.Render(fmt.Sprintf("%5s ", lineNumber(no)))
+	codeWidth := max(0, width-lipgloss.Width(gutter)-1)
+	return gutter + lipgloss.NewStyle().Background(bg).Render(marker) + renderCode(d.codeLine(*line), codeWidth, bg)
+}
+
+func lineNumber(no int) string {
+	if no == 0 {
+		return ""
+	}
+	return fmt.Sprint(no)
+}
+
+// renderDiffRow renders one row of the diff at the given width
+func (d fileDiff) renderDiffRow(row diffRow, width int, sideBySide bool) string {
+	if row.header {
+		return diffHunkStyle.Render(truncate(d.hunks[row.hunk].header(), width))
+	}
+	if sideBySide {
+		half := (width - 1) / 2
+		return d.renderDiffSide(row.left, half, true) + sepStyle.Render("│") + d.renderDiffSide(row.right, width-half-1, false)
+	}
+	// Unified rows show both line numbers
+	line := row.left
+	old := sepStyle.Render(fmt.Sprintf("%5s", lineNumber(line.oldNo)))
+	return old + d.renderDiffSide(line, width-5, false)
+}
+
+// changeBadge renders the one-letter change type shown in the files list
+func changeBadge(changeType string) string {
+	switch changeType {
+	case "add":
+		return prGreen.Render("A")
+	case "delete":
+		return requiredStyle.UnsetWidth().Render("D")
+	case "rename":
+		return voteStyle.UnsetWidth().Render("R")
+	default:
+		return diffHunkStyle.Render("M")
+	}
+}
+
+// renderFiles renders the changed files of the selected PR
+func (m tuiModel) renderFiles(width, height int) (string, paneLayout) {
+	frameWidth, frameHeight := boxStyle.GetFrameSize()
+	usableWidth := width - frameWidth
+	title := fmt.Sprintf("Files changed in !%d", m.files.pr.id)
+	switch {
+	case m.files.sinceVote && !m.files.loading && m.files.err == nil:
+		title = fmt.Sprintf("Changes in !%d since iteration %d (your last vote)", m.files.pr.id, m.files.compareTo)
+	case m.files.compareTo > 0:
+		title = fmt.Sprintf("Changes in !%d, iteration %d since %d", m.files.pr.id, m.files.iteration, m.files.compareTo)
+	}
+	header := titleStyle.UnsetHeight().UnsetMarginBottom().Width(usableWidth).Render(title)
+	lines := []string{header, ""}
+	pane := paneLayout{}
+	switch {
+	case m.files.err != nil:
+		lines = append(lines, errorStyle.Render(truncate(m.files.err.Error(), usableWidth)))
+	case m.files.loading:
+		lines = append(lines, "Loading changes...")
+	case len(m.files.changes) == 0:
+		lines = append(lines, "No file changes.")
+	default:
+		visible := max(1, height-frameHeight-len(lines))
+		first := 0
+		if m.files.selected >= visible {
+			first = m.files.selected - visible + 1
+		}
+		last := min(len(m.files.changes), first+visible)
+		pane.first = first
+		pane.content = rect{
+			x: boxStyle.GetMarginLeft() + boxStyle.GetBorderLeftSize() + boxStyle.GetPaddingLeft(),
+			y: boxStyle.GetBorderTopSize() + boxStyle.GetPaddingTop() + len(lines),
+			w: usableWidth,
+			h: last - first,
+		}
+		for i := first; i < last; i++ {
+			change := m.files.changes[i]
+			cursor := " "
+			if i == m.files.selected {
+				cursor = ">"
+			}
+			path := change.path
+			if change.changeType == "rename" && change.originalPath != "" {
+				path = change.originalPath + " → " + change.path
+			}
+			line := cursor + " " + changeBadge(change.changeType) + " " + truncate(path, usableWidth-4)
+			if i == m.files.selected {
+				line = selectedStyle.Render(line)
+			}
+			lines = append(lines, line)
+		}
+	}
+	box := boxStyle.Width(width - boxStyle.GetHorizontalMargins() - boxStyle.GetHorizontalBorderSize()).Render(strings.Join(lines, "\n"))
+	pane.area = rect{w: lipgloss.Width(box), h: lipgloss.Height(box)}
+	return box, pane
+}
+
+// renderDiff renders the scrolled diff of the open file
+func (m tuiModel) renderDiff(width, height int) (string, paneLayout) {
+	d := m.diff
+	change := d.change
+	path := change.path
+	if change.changeType == "rename" && change.originalPath != "" {
+		path = change.originalPath + " → " + change.path
+	}
+	rows := buildDiffRows(d.diff, m.sideBySide())
+	position := ""
+	if len(d.diff.hunks) > 0 {
+		position = fmt.Sprintf(" (hunk %d/%d)", m.currentHunk(rows)+1, len(d.diff.hunks))
+	}
+	lines := []string{selectedStyle.Render(truncate(path+position, width))}
+	bodyHeight := max(1, height-len(lines))
+	pane := paneLayout{}
+	switch {
+	case d.err != nil:
+		lines = append(lines, errorStyle.Render(truncate(d.err.Error(), width)))
+	case d.loading:
+		lines = append(lines, "Loading diff...")
+	case d.diff.binary:
+		lines = append(lines, "Binary file, no diff shown.")
+	case d.diff.tooLarge:
+		lines = append(lines, "File is too large to diff.")
+	case len(rows) == 0:
+		lines = append(lines, "No changes.")
+	default:
+		pane.maxScroll = max(0, len(rows)-bodyHeight)
+		scroll := min(d.scroll, pane.maxScroll)
+		for _, row := range rows[scroll:min(len(rows), scroll+bodyHeight)] {
+			lines = append(lines, d.diff.renderDiffRow(row, width, m.sideBySide()))
+		}
+	}
+	view := strings.Join(lines, "\n")
+	pane.area = rect{w: width, h: lipgloss.Height(view)}
+	return view, pane
+}
+
+// sideBySide reports whether the diff is shown side by side, which needs a wide terminal
+func (m tuiModel) sideBySide() bool {
+	return m.diff.sideBySide && chooseLayout(m.width) == layoutWide
+}
+
+// currentHunk returns the hunk at the top of the diff view
+func (m tuiModel) currentHunk(rows []diffRow) int {
+	if len(rows) == 0 {
+		return 0
+	}
+	return rows[min(m.diff.scroll, len(rows)-1)].hunk
+}
+
+// hunkRow returns the row of the header of the next (dir 1) or previous (dir -1) hunk
+func (m tuiModel) hunkRow(dir int) int {
+	rows := buildDiffRows(m.diff.diff, m.sideBySide())
+	current := m.diff.scroll
+	if dir > 0 {
+		for i := current + 1; i < len(rows); i++ {
+			if rows[i].header {
+				return i
+			}
+		}
+		return current
+	}
+	for i := min(current, len(rows)) - 1; i >= 0; i-- {
+		if rows[i].header {
+			return i
+		}
+	}
+	return current
+}
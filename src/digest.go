@@ -0,0 +1,313 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// digestItem is one PR line of the digest
+type digestItem struct {
+	pr     PullRequestInfo
+	detail string
+}
+
+// digestSection is a titled list of PRs, with a note shown when it is empty
+type digestSection struct {
+	title  string
+	empty  string
+	groups []digestGroup
+}
+
+// digestGroup is a run of items under an optional heading, e.g. a reviewer's name
+type digestGroup struct {
+	heading string
+	items   []digestItem
+}
+
+// digest is the standup report of `AzurePR digest`
+type digest struct {
+	title    string
+	sections []digestSection
+}
+
+// buildDigest sorts the open PRs and the ones completed since the given time
+// into the sections of the digest. lastActivity holds when each open PR last
+// saw activity, like the age column of the TUI.
+func buildDigest(open, completed []PullRequestInfo, lastActivity map[int]time.Time, userID string, ages agePolicy, since, now time.Time) digest {
+	waiting := func(pr PullRequestInfo) time.Duration {
+		from := pr.createdDate
+		if last, ok := lastActivity[pr.id]; ok && last.After(from) {
+			from = last
+		}
+		return ages.age(from, now)
+	}
+	// Longest waiting first everywhere
+	sort.SliceStable(open, func(i, j int) bool { return waiting(open[i]) > waiting(open[j]) })
+
+	awaiting := make(map[string][]digestItem)
+	var mine, stale []digestItem
+	for _, pr := range open {
+		age := formatAge(waiting(pr))
+		if pr.creatorID == userID {
+			mine = append(mine, digestItem{pr: pr, detail: voteSummary(pr)})
+		}
+		if pr.IsDraft {
+			continue
+		}
+		for _, rev := range pr.reviewers {
+			if rev.vote != 0 || rev.id == pr.creatorID || ignoredReviewerIDs[rev.id] {
+				continue
+			}
+			detail := fmt.Sprintf("%s, by %s, waiting %s", pr.repository, pr.creator, age)
+			if rev.isRequired {
+				detail += ", required"
+			}
+			awaiting[rev.displayName] = append(awaiting[rev.displayName], digestItem{pr: pr, detail: detail})
+		}
+		if waiting(pr) >= ages.stale {
+			stale = append(stale, digestItem{pr: pr, detail: fmt.Sprintf("%s, by %s, waiting %s", pr.repository, pr.creator, age)})
+		}
+	}
+
+	reviewers := make([]string, 0, len(awaiting))
+	for name := range awaiting {
+		reviewers = append(reviewers, name)
+	}
+	sort.Slice(reviewers, func(i, j int) bool { return strings.ToLower(reviewers[i]) < strings.ToLower(reviewers[j]) })
+	awaitingSection := digestSection{title: "Awaiting review", empty: "No PRs are waiting for a review."}
+	for _, name := range reviewers {
+		awaitingSection.groups = append(awaitingSection.groups, digestGroup{heading: name, items: awaiting[name]})
+	}
+
+	sort.SliceStable(completed, func(i, j int) bool { return completed[i].closedDate.Before(completed[j].closedDate) })
+	var merged []digestItem
+	for _, pr := range completed {
+		merged = append(merged, digestItem{pr: pr, detail: fmt.Sprintf("%s, by %s; %s", pr.repository, pr.creator, closedLabel(pr))})
+	}
+
+	return digest{
+		title: "Pull request digest, " + now.Format("Monday 2 January 2006"),
+		sections: []digestSection{
+			awaitingSection,
+			{title: "My PRs", empty: "You have no open PRs.", groups: []digestGroup{{items: mine}}},
+			{title: fmt.Sprintf("Waiting longer than %g hours", ages.stale.Hours()), empty: "No PRs are over the limit.", groups: []digestGroup{{items: stale}}},
+			{title: "Completed since " + since.Format("Monday 2 January 15:04"), empty: "No PRs were completed.", groups: []digestGroup{{items: merged}}},
+		},
+	}
+}
+
+// voteSummary lists the reviewers of a PR by vote, e.g. "Approved: Bob; No vote: Eve (required)"
+func voteSummary(pr PullRequestInfo) string {
+	var parts []string
+	if pr.IsDraft {
+		parts = append(parts, "draft")
+	}
+	// Strongest votes first, as in the web UI
+	for _, vote := range []int{-10, -5, 10, 5, 0} {
+		var names []string
+		for _, rev := range pr.reviewers {
+			if rev.vote != vote || ignoredReviewerIDs[rev.id] {
+				continue
+			}
+			name := rev.displayName
+			if rev.isRequired {
+				name += " (required)"
+			}
+			names = append(names, name)
+		}
+		if len(names) > 0 {
+			parts = append(parts, voteLabel(vote)+": "+strings.Join(names, ", "))
+		}
+	}
+	if len(parts) == 0 {
+		return "no reviewers"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// isEmpty reports whether the section lists no PRs
+func (s digestSection) isEmpty() bool {
+	for _, g := range s.groups {
+		if len(g.items) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// markdownEscaper escapes the characters that would format PR titles in Markdown
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "#", `\#`, "|", `\|`)
+
+// writeMarkdown writes the digest as Markdown, linking every PR
+func (d digest) writeMarkdown(w io.Writer) {
+	fmt.Fprintf(w, "# %s\n", d.title)
+	for _, section := range d.sections {
+		fmt.Fprintf(w, "\n## %s\n\n", section.title)
+		if section.isEmpty() {
+			fmt.Fprintf(w, "_%s_\n", section.empty)
+			continue
+		}
+		for i, group := range section.groups {
+			if group.heading != "" {
+				if i > 0 {
+					fmt.Fprintln(w)
+				}
+				fmt.Fprintf(w, "**%s**\n\n", markdownEscaper.Replace(group.heading))
+			}
+			for _, item := range group.items {
+				ref := fmt.Sprintf("!%d", item.pr.id)
+				if item.pr.url != "" {
+					ref = fmt.Sprintf("[!%d](%s)", item.pr.id, item.pr.url)
+				}
+				fmt.Fprintf(w, "- %s %s (%s)\n", ref, markdownEscaper.Replace(item.pr.title), markdownEscaper.Replace(item.detail))
+			}
+		}
+	}
+}
+
+// writeText writes the digest as plain text
+func (d digest) writeText(w io.Writer) {
+	fmt.Fprintln(w, d.title)
+	fmt.Fprintln(w, strings.Repeat("=", len([]rune(d.title))))
+	for _, section := range d.sections {
+		fmt.Fprintf(w, "\n%s\n%s\n", section.title, strings.Repeat("-", len([]rune(section.title))))
+		if section.isEmpty() {
+			fmt.Fprintln(w, section.empty)
+			continue
+		}
+		for _, group := range section.groups {
+			indent := ""
+			if group.heading != "" {
+				fmt.Fprintf(w, "%s:\n", group.heading)
+				indent = "  "
+			}
+			for _, item := range group.items {
+				fmt.Fprintf(w, "%s- !%d %s (%s)\n", indent, item.pr.id, item.pr.title, item.detail)
+			}
+		}
+	}
+}
+
+// lastWorkdayStart returns the start of the working day days before now's,
+// skipping the days the age policy leaves out
+func lastWorkdayStart(ages agePolicy, now time.Time, days int) time.Time {
+	y, m, d := now.Local().Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	for days > 0 {
+		day = day.AddDate(0, 0, -1)
+		if ages.counts(day) {
+			days--
+		}
+	}
+	return day
+}
+
+// RunDigest implements `AzurePR digest`, printing a report of the review state
+// for a standup. The PRs are filtered like the TUI's list, with a flag for each
+// toggle.
+func RunDigest(session *Session, userID string, args []string, ages agePolicy) error {
+	flags := flag.NewFlagSet("digest", flag.ContinueOnError)
+	format := flags.String("format", "markdown", "markdown or text")
+	days := flags.Int("days", 1, "list PRs completed since the start of this many working days ago")
+	filter := prFilter{userID: userID}
+	flags.BoolVar(&filter.drafts, "drafts", false, "show drafts (d in the TUI)")
+	flags.BoolVar(&filter.mine, "mine", true, "show your own PRs (m in the TUI, but on by default for the My PRs section)")
+	flags.BoolVar(&filter.notReviewer, "all", false, "show PRs you are not a reviewer of (r in the TUI)")
+	flags.BoolVar(&filter.blocking, "blocking", false, "only PRs with a blocking policy not passing (b in the TUI)")
+	flags.BoolVar(&filter.unlinked, "unlinked", false, "only PRs without linked work items (l in the TUI)")
+	flags.StringVar(&filter.query, "filter", "", `only PRs matching this filter, as typed after "/" in the TUI (e.g. "label:backend")`)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	write := digest.writeMarkdown
+	switch *format {
+	case "markdown", "md":
+	case "text", "txt":
+		write = digest.writeText
+	default:
+		return fmt.Errorf("unknown format %q (available: markdown, text)", *format)
+	}
+	now := time.Now()
+	since := lastWorkdayStart(ages, now, *days)
+	open, err := session.ListPullRequests(statusActive, time.Time{})
+	if err != nil {
+		return err
+	}
+	completed, err := session.ListPullRequests(statusCompleted, since)
+	if err != nil {
+		return err
+	}
+	// Like the TUI, checks are only fetched for open PRs
+	blocking, unlinked := map[int]bool{}, map[int]bool{}
+	if filter.blocking {
+		blocking = fetchEach(open, func(pr PullRequestInfo) (bool, error) {
+			checks, err := session.PullRequestChecks(pr)
+			return prChecks{items: checks}.blocking(), err
+		})
+	}
+	if filter.unlinked {
+		unlinked = fetchEach(append(open, completed...), func(pr PullRequestInfo) (bool, error) {
+			items, err := session.ListWorkItems(pr)
+			return len(items) == 0, err
+		})
+	}
+	isBlocking := func(prID int) bool { return blocking[prID] }
+	isUnlinked := func(prID int) bool { return unlinked[prID] }
+	open, completed = filter.apply(open, isBlocking, isUnlinked), filter.apply(completed, isBlocking, isUnlinked)
+	write(buildDigest(open, completed, session.lastActivities(open, userID), userID, ages, since, now), os.Stdout)
+	return nil
+}
+
+// fetchEach asks check about every PR concurrently, returning the ids of the
+// PRs it held for. PRs it failed for are left out, as the TUI does.
+func fetchEach(prs []PullRequestInfo, check func(PullRequestInfo) (bool, error)) map[int]bool {
+	ids := make(map[int]bool)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, pr := range prs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checksLimit <- struct{}{}
+			defer func() { <-checksLimit }()
+			if ok, err := check(pr); err == nil && ok {
+				mu.Lock()
+				ids[pr.id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return ids
+}
+
+// lastActivities fetches when each PR last saw activity, leaving out PRs whose
+// activity could not be read so their age falls back to their creation
+func (s *Session) lastActivities(prs []PullRequestInfo, userID string) map[int]time.Time {
+	last := make(map[int]time.Time, len(prs))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, pr := range prs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checksLimit <- struct{}{}
+			defer func() { <-checksLimit }()
+			activity, err := s.PullRequestActivity(pr, userID)
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			last[pr.id] = activity.last
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return last
+}
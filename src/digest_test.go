@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sectionLines summarizes each section of a digest as "heading: !id detail" lines
+func sectionLines(d digest) map[string][]string {
+	lines := make(map[string][]string)
+	for _, section := range d.sections {
+		lines[section.title] = []string{}
+		for _, group := range section.groups {
+			for _, item := range group.items {
+				line := fmt.Sprintf("!%d %s", item.pr.id, item.detail)
+				if group.heading != "" {
+					line = group.heading + ": " + line
+				}
+				lines[section.title] = append(lines[section.title], line)
+			}
+		}
+	}
+	return lines
+}
+
+func TestBuildDigest(t *testing.T) {
+	now := time.Date(2026, 10, 21, 12, 0, 0, 0, time.Local) // a Wednesday
+	since := time.Date(2026, 10, 20, 0, 0, 0, 0, time.Local)
+	ages, err := newAgePolicy(StalenessConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	me := PullrequestReviewer{id: "me", displayName: "Me"}
+	bob := PullrequestReviewer{id: "bob", displayName: "bob"}
+	eve := PullrequestReviewer{id: "eve", displayName: "Eve", isRequired: true}
+	ignored := PullrequestReviewer{id: "1809cf47-1683-62b4-ab66-9dbfd3d291d6", displayName: "Build Service"}
+	voted := func(r PullrequestReviewer, vote int) PullrequestReviewer {
+		r.vote = vote
+		return r
+	}
+	open := func(id int, creator string, age time.Duration, reviewers ...PullrequestReviewer) PullRequestInfo {
+		return PullRequestInfo{id: id, title: fmt.Sprint("PR ", id), repository: "web", creator: creator, creatorID: strings.ToLower(creator),
+			status: "active", createdDate: now.Add(-age), reviewers: reviewers}
+	}
+	completed := func(id int, hoursAgo int) PullRequestInfo {
+		return PullRequestInfo{id: id, repository: "api", creator: "Ada", status: "completed", closedBy: "Bob", closedDate: now.Add(-time.Duration(hoursAgo) * time.Hour)}
+	}
+	draft := open(5, "Ada", time.Hour, me)
+	draft.IsDraft = true
+	myDraft := open(6, "Me", time.Hour, bob)
+	myDraft.IsDraft = true
+
+	tests := []struct {
+		name         string
+		open         []PullRequestInfo
+		completed    []PullRequestInfo
+		lastActivity map[int]time.Time
+		want         map[string][]string
+	}{
+		{
+			name: "empty",
+			want: map[string][]string{"Awaiting review": {}, "My PRs": {}, "Waiting longer than 24 hours": {}, "Completed since Tuesday 20 October 00:00": {}},
+		},
+		{
+			name: "awaiting review grouped by reviewer, longest waiting first",
+			open: []PullRequestInfo{
+				open(1, "Ada", 2*time.Hour, me, eve),
+				open(2, "Ada", 5*time.Hour, me, voted(bob, 10), ignored),
+				open(3, "Bob", 3*time.Hour, bob, voted(eve, -5)),
+				draft,
+			},
+			want: map[string][]string{
+				"Awaiting review": {
+					"Eve: !1 web, by Ada, waiting 2h, required",
+					"Me: !2 web, by Ada, waiting 5h",
+					"Me: !1 web, by Ada, waiting 2h",
+				},
+				"My PRs": {}, "Waiting longer than 24 hours": {}, "Completed since Tuesday 20 October 00:00": {},
+			},
+		},
+		{
+			name: "my PRs with their votes, drafts included",
+			open: []PullRequestInfo{open(4, "Me", time.Hour, voted(bob, 10), eve, voted(me, 5)), myDraft},
+			want: map[string][]string{
+				"Awaiting review": {"Eve: !4 web, by Me, waiting 1h, required"},
+				"My PRs": {
+					"!4 Approved: bob; Suggest: Me; No Vote: Eve (required)",
+					"!6 draft; No Vote: bob",
+				},
+				"Waiting longer than 24 hours": {}, "Completed since Tuesday 20 October 00:00": {},
+			},
+		},
+		{
+			name:         "stale by last activity",
+			open:         []PullRequestInfo{open(7, "Ada", 72*time.Hour, voted(bob, 10)), open(8, "Ada", 30*time.Hour, voted(bob, 10))},
+			lastActivity: map[int]time.Time{8: now.Add(-time.Hour), 7: now.Add(-100 * time.Hour)},
+			want: map[string][]string{
+				"Awaiting review": {}, "My PRs": {},
+				"Waiting longer than 24 hours":             {"!7 web, by Ada, waiting 3d"},
+				"Completed since Tuesday 20 October 00:00": {},
+			},
+		},
+		{
+			name:      "completed oldest first",
+			completed: []PullRequestInfo{completed(10, 2), completed(9, 20)},
+			want: map[string][]string{
+				"Awaiting review": {}, "My PRs": {}, "Waiting longer than 24 hours": {},
+				"Completed since Tuesday 20 October 00:00": {
+					"!9 api, by Ada; Merged " + now.Add(-20*time.Hour).Format("2006-01-02 15:04") + " by Bob",
+					"!10 api, by Ada; Merged " + now.Add(-2*time.Hour).Format("2006-01-02 15:04") + " by Bob",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := buildDigest(tt.open, tt.completed, tt.lastActivity, "me", ages, since, now)
+			if d.title != "Pull request digest, Wednesday 21 October 2026" {
+				t.Errorf("title = %q", d.title)
+			}
+			if got := sectionLines(d); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("sections = %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLastWorkdayStart(t *testing.T) {
+	day := func(d, hour int) time.Time { return time.Date(2026, 10, d, hour, 0, 0, 0, time.Local) }
+	tests := []struct {
+		name   string
+		config StalenessConfig
+		now    time.Time
+		days   int
+		want   time.Time
+	}{
+		{name: "today", now: day(21, 9), days: 0, want: day(21, 0)},
+		{name: "yesterday", now: day(21, 9), days: 1, want: day(20, 0)},
+		{name: "monday counts the weekend", now: day(19, 9), days: 1, want: day(18, 0)},
+		{name: "monday skipping weekends", config: StalenessConfig{SkipWeekends: true}, now: day(19, 9), days: 1, want: day(16, 0)},
+		{name: "two working days over a weekend", config: StalenessConfig{SkipWeekends: true}, now: day(20, 9), days: 2, want: day(16, 0)},
+		{name: "holiday", config: StalenessConfig{Holidays: []string{"2026-10-20"}}, now: day(21, 9), days: 1, want: day(19, 0)},
+		{name: "holiday before a weekend", config: StalenessConfig{SkipWeekends: true, Holidays: []string{"2026-10-16"}}, now: day(19, 9), days: 1, want: day(15, 0)},
+		{name: "weekend day itself", config: StalenessConfig{SkipWeekends: true}, now: day(18, 9), days: 1, want: day(16, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ages, err := newAgePolicy(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := lastWorkdayStart(ages, tt.now, tt.days); !got.Equal(tt.want) {
+				t.Fatalf("lastWorkdayStart() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		d    digest
+		want []string
+	}{
+		{
+			name: "escapes titles and details",
+			d: digest{title: "Digest", sections: []digestSection{{title: "Awaiting review", groups: []digestGroup{{
+				heading: "[Team]_A",
+				items: []digestItem{{
+					pr:     PullRequestInfo{id: 1, title: "Fix *bold* _it_ `code` [link](x) #1 a|b <b>", url: "https://x/1"},
+					detail: "by snake_case",
+				}},
+			}}}}},
+			want: []string{
+				"# Digest",
+				"## Awaiting review",
+				`**\[Team\]\_A**`,
+				"- [!1](https://x/1) Fix \\*bold\\* \\_it\\_ \\`code\\` \\[link\\](x) \\#1 a\\|b \\<b\\> (by snake\\_case)",
+			},
+		},
+		{
+			name: "no link without url",
+			d:    digest{title: "Digest", sections: []digestSection{{title: "My PRs", groups: []digestGroup{{items: []digestItem{{pr: PullRequestInfo{id: 2, title: "Plain"}, detail: "no reviewers"}}}}}}},
+			want: []string{"- !2 Plain (no reviewers)"},
+		},
+		{
+			name: "empty section",
+			d:    digest{title: "Digest", sections: []digestSection{{title: "My PRs", empty: "You have no open PRs.", groups: []digestGroup{{}}}}},
+			want: []string{"_You have no open PRs._"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			tt.d.writeMarkdown(&out)
+			lines := strings.Split(out.String(), "\n")
+			for _, want := range tt.want {
+				found := false
+				for _, line := range lines {
+					found = found || line == want
+				}
+				if !found {
+					t.Errorf("missing line %q in:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestPRFilter(t *testing.T) {
+	mineOpen := PullRequestInfo{id: 1, creatorID: "me"}
+	mineDraft := PullRequestInfo{id: 2, creatorID: "me", IsDraft: true}
+	reviewing := PullRequestInfo{id: 3, creatorID: "ada", reviewers: []PullrequestReviewer{{id: "me"}}, labels: []string{"backend"}}
+	reviewingDraft := PullRequestInfo{id: 4, creatorID: "ada", IsDraft: true, reviewers: []PullrequestReviewer{{id: "me"}}}
+	others := PullRequestInfo{id: 5, creatorID: "ada"}
+	all := []PullRequestInfo{mineOpen, mineDraft, reviewing, reviewingDraft, others}
+	blocked := map[int]bool{3: true, 5: true}
+	unlinked := map[int]bool{1: true, 5: true}
+
+	tests := []struct {
+		name   string
+		filter prFilter
+		want   []int
+	}{
+		{name: "tui defaults", filter: prFilter{}, want: []int{3}},
+		{name: "drafts", filter: prFilter{drafts: true}, want: []int{3, 4}},
+		{name: "mine hides my drafts", filter: prFilter{mine: true}, want: []int{3, 1}},
+		{name: "mine with drafts", filter: prFilter{mine: true, drafts: true}, want: []int{3, 4, 1, 2}},
+		{name: "not reviewer", filter: prFilter{notReviewer: true}, want: []int{3, 5}},
+		{name: "everything", filter: prFilter{notReviewer: true, mine: true, drafts: true}, want: []int{1, 2, 3, 4, 5}},
+		{name: "blocking", filter: prFilter{notReviewer: true, mine: true, blocking: true}, want: []int{3, 5}},
+		{name: "unlinked", filter: prFilter{notReviewer: true, mine: true, unlinked: true}, want: []int{1, 5}},
+		{name: "query", filter: prFilter{notReviewer: true, query: "label:backend"}, want: []int{3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.userID = "me"
+			prs := tt.filter.apply(all, func(id int) bool { return blocked[id] }, func(id int) bool { return unlinked[id] })
+			var got []int
+			for _, pr := range prs {
+				got = append(got, pr.id)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
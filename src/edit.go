@@ -0,0 +1,180 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aymanbagabas/go-udiff"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+)
+
+// Fields of the edit form
+const (
+	editTitle = iota
+	editDescription
+	editTarget
+)
+
+// editLoadedMsg carries the full PR to edit
+type editLoadedMsg struct {
+	pr  PullRequestInfo
+	err error
+}
+
+// GetPullRequest fetches a single pull request. Unlike the PR list it has the
+// whole description.
+func (s *Session) GetPullRequest(id int) (PullRequestInfo, error) {
+	pr, err := s.gitClient.GetPullRequestById(s.ctx, git.GetPullRequestByIdArgs{
+		PullRequestId: &id,
+		Project:       &s.project,
+	})
+	if err != nil {
+		return PullRequestInfo{}, err
+	}
+	return createPullRequestInfo(pr), nil
+}
+
+// UpdatePullRequestDetails saves a new title, description and target branch,
+// sending only what differs from pr
+func (s *Session) UpdatePullRequestDetails(pr, updated PullRequestInfo) error {
+	update := &git.GitPullRequest{}
+	if updated.title != pr.title {
+		update.Title = &updated.title
+	}
+	if updated.description != pr.description {
+		update.Description = &updated.description
+	}
+	if updated.targetBranch != pr.targetBranch {
+		target := refName(updated.targetBranch)
+		update.TargetRefName = &target
+	}
+	_, err := s.gitClient.UpdatePullRequest(s.ctx, git.UpdatePullRequestArgs{
+		GitPullRequestToUpdate: update,
+		RepositoryId:           &pr.repositoryID,
+		PullRequestId:          &pr.id,
+		Project:                &s.project,
+	})
+	return err
+}
+
+// openEdit loads the selected PR in full before showing the edit form
+func (m tuiModel) openEdit() (tuiModel, tea.Cmd) {
+	pr, ok := m.selectedPR()
+	if !ok {
+		return m, nil
+	}
+	if pr.closed() {
+		return m, func() tea.Msg {
+			return statusMsg{err: fmt.Errorf("!%d is %s and can no longer be edited", pr.id, pr.status)}
+		}
+	}
+	session := m.session
+	m.status = statusMsg{text: fmt.Sprintf("Loading !%d...", pr.id)}
+	return m, func() tea.Msg {
+		if session == nil {
+			return editLoadedMsg{err: errors.New("not connected")}
+		}
+		full, err := session.GetPullRequest(pr.id)
+		return editLoadedMsg{pr: full, err: err}
+	}
+}
+
+// openEditForm shows the title, description and target branch of a PR for editing
+func (m tuiModel) openEditForm(pr PullRequestInfo) tuiModel {
+	description := textField("Description", pr.description)
+	description.multiline = true
+	return m.openForm(formState{
+		kind:   formEdit,
+		title:  fmt.Sprintf("Edit !%d (%s into %s)", pr.id, pr.sourceBranch, pr.targetBranch),
+		submit: "Preview",
+		pr:     pr,
+		fields: []formField{
+			textField("Title", pr.title),
+			description,
+			textField("Target", pr.targetBranch),
+		},
+	})
+}
+
+// editedPR returns the PR of the edit form with the form's values applied
+func (m tuiModel) editedPR() PullRequestInfo {
+	pr := m.form.pr
+	pr.title = m.form.fields[editTitle].value()
+	pr.description = m.form.fields[editDescription].value()
+	pr.targetBranch = branchName(m.form.fields[editTarget].value())
+	return pr
+}
+
+// previewEdit checks the pending edit and shows what it changes before it is saved
+func (m tuiModel) previewEdit() tuiModel {
+	pr, updated := m.form.pr, m.editedPR()
+	var err error
+	switch {
+	case updated.title == "":
+		err = errors.New("the title cannot be empty")
+	case updated.targetBranch == "":
+		err = errors.New("the target branch cannot be empty")
+	case updated.targetBranch == pr.sourceBranch:
+		err = fmt.Errorf("the target cannot be the source branch %s", pr.sourceBranch)
+	}
+	var preview []string
+	preview = append(preview, editDiff("Title", pr.title, updated.title)...)
+	preview = append(preview, editDiff("Description", pr.description, updated.description)...)
+	preview = append(preview, editDiff("Target", pr.targetBranch, updated.targetBranch)...)
+	if err == nil && len(preview) == 0 {
+		err = errors.New("nothing has changed")
+	}
+	m.form.err = err
+	if err != nil {
+		return m
+	}
+	m.form.preview = preview
+	m.form.submit = "Save"
+	m.form.focus = len(m.form.fields)
+	return m
+}
+
+// editDiff shows the changed lines of one field as a diff, or nothing when it is unchanged
+func editDiff(label, before, after string) []string {
+	if before == after {
+		return nil
+	}
+	// Compare whole lines so a missing final newline does not show up as a change
+	before, after = before+"\n", after+"\n"
+	unified, err := udiff.ToUnifiedDiff(label, label, before, udiff.Strings(before, after), 1)
+	if err != nil {
+		return nil
+	}
+	lines := []string{label + ":"}
+	for _, hunk := range unified.Hunks {
+		for _, line := range hunk.Lines {
+			text := strings.TrimRight(line.Content, "\r\n")
+			switch line.Kind {
+			case udiff.Delete:
+				lines = append(lines, lipgloss.NewStyle().Background(diffDelBg).Render("- "+text))
+			case udiff.Insert:
+				lines = append(lines, lipgloss.NewStyle().Background(diffAddBg).Render("+ "+text))
+			default:
+				lines = append(lines, "  "+text)
+			}
+		}
+	}
+	return lines
+}
+
+// editCmd saves the previewed edit
+func (m tuiModel) editCmd() tea.Cmd {
+	session, pr, updated := m.session, m.form.pr, m.editedPR()
+	return func() tea.Msg {
+		if session == nil {
+			return formDoneMsg{kind: formEdit, pr: pr, err: errors.New("not connected")}
+		}
+		if err := session.UpdatePullRequestDetails(pr, updated); err != nil {
+			return formDoneMsg{kind: formEdit, pr: pr, err: err}
+		}
+		return formDoneMsg{kind: formEdit, pr: updated, text: fmt.Sprintf("Saved !%d", pr.id)}
+	}
+}
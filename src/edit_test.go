@@ -0,0 +1,23 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEditRefusesClosedPRs(t *testing.T) {
+	for _, status := range []string{"completed", "abandoned"} {
+		t.Run(status, func(t *testing.T) {
+			pr := reviewedByMe(1, "First")
+			pr.status = status
+			m, cmd := testModel(pr).openEdit()
+			if m.status.text != "" {
+				t.Fatalf("status = %q, want no load started", m.status.text)
+			}
+			msg, ok := cmd().(statusMsg)
+			if !ok || msg.err == nil || !strings.Contains(msg.err.Error(), "can no longer be edited") {
+				t.Fatalf("got %#v, want an error", msg)
+			}
+		})
+	}
+}
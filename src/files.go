@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// viewMode is the screen currently shown by the TUI
+type viewMode int
+
+const (
+	viewPRs viewMode = iota
+	viewFiles
+	viewDiff
+	viewIterations
+	viewWorkItems
+	viewForm
+	viewReviewers
+)
+
+// filesState is the changed files list of one PR
+type filesState struct {
+	pr        PullRequestInfo
+	iteration int
+	compareTo int // iteration the changes are relative to, 0 for the branch base
+	sinceVote bool
+	seq       int      // number of the load filling the list, see nextLoad
+	back      viewMode // view to return to
+	changes   []fileChange
+	selected  int
+	loading   bool
+	err       error
+}
+
+// diffState is the diff of one changed file
+type diffState struct {
+	change     fileChange
+	diff       fileDiff
+	scroll     int
+	sideBySide bool
+	seq        int // number of the load filling the diff, see nextLoad
+	loading    bool
+	err        error
+}
+
+type filesLoadedMsg struct {
+	seq       int
+	iteration int
+	compareTo int
+	changes   []fileChange
+	err       error
+}
+
+type diffLoadedMsg struct {
+	seq  int
+	diff fileDiff
+	err  error
+}
+
+// nextLoad numbers a files, diff or iterations load. Only the load with the
+// number of the open view fills it, so a slow earlier load cannot overwrite a
+// later one.
+func (m *tuiModel) nextLoad() int {
+	m.loadSeq++
+	return m.loadSeq
+}
+
+// loadFilesCmd fetches the files changed by the latest iteration of the PR
+func loadFilesCmd(session *Session, pr PullRequestInfo, seq int) tea.Cmd {
+	return func() tea.Msg {
+		iteration, err := session.LatestIterationID(pr)
+		if err != nil {
+			return filesLoadedMsg{seq: seq, err: err}
+		}
+		changes, err := session.ListPullRequestChanges(pr, iteration, 0)
+		return filesLoadedMsg{seq: seq, iteration: iteration, changes: changes, err: err}
+	}
+}
+
+// loadDiffCmd fetches and diffs both versions of a changed file
+func loadDiffCmd(session *Session, pr PullRequestInfo, change fileChange, seq int) tea.Cmd {
+	return func() tea.Msg {
+		diff, err := session.FileDiff(pr, change)
+		return diffLoadedMsg{seq: seq, diff: diff, err: err}
+	}
+}
+
+// openFiles switches to the files view of the selected PR and starts loading it
+func (m tuiModel) openFiles() (tuiModel, tea.Cmd) {
+	pr, ok := m.selectedPR()
+	if !ok {
+		return m, nil
+	}
+	m.view = viewFiles
+	m.files = filesState{pr: pr, loading: true, seq: m.nextLoad()}
+	return m, loadFilesCmd(m.session, pr, m.files.seq)
+}
+
+// updateFiles handles keys in the files view
+func (m tuiModel) updateFiles(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		m.files.selected = max(0, m.files.selected-1)
+	case key.Matches(msg, m.keys.Down):
+		m.files.selected = max(0, min(m.files.selected+1, len(m.files.changes)-1))
+	case key.Matches(msg, m.keys.Details):
+		if m.files.selected < len(m.files.changes) {
+			change := m.files.changes[m.files.selected]
+			m.view = viewDiff
+			m.diff = diffState{change: change, loading: true, sideBySide: m.diff.sideBySide, seq: m.nextLoad()}
+			return m, loadDiffCmd(m.session, m.files.pr, change, m.diff.seq)
+		}
+	case key.Matches(msg, m.keys.OpenBrowser):
+		return m, openBrowserCmd(m.files.pr.url + "?_a=files")
+	case key.Matches(msg, m.keys.Help):
+		m.showHelp = true
+	case key.Matches(msg, m.keys.Quit):
+		m.view = m.files.back
+	}
+	return m, nil
+}
+
+// updateDiff handles keys in the diff view
+func (m tuiModel) updateDiff(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	_, layout := m.render()
+	page := max(1, layout.diff.area.h-1)
+	scroll := func(delta int) {
+		m.diff.scroll = max(0, min(m.diff.scroll+delta, layout.diff.maxScroll))
+	}
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		scroll(-1)
+	case key.Matches(msg, m.keys.Down):
+		scroll(1)
+	case key.Matches(msg, m.keys.PageUp):
+		scroll(-page)
+	case key.Matches(msg, m.keys.PageDown):
+		scroll(page)
+	case key.Matches(msg, m.keys.NextHunk):
+		m.diff.scroll = m.hunkRow(1)
+	case key.Matches(msg, m.keys.PrevHunk):
+		m.diff.scroll = m.hunkRow(-1)
+	case key.Matches(msg, m.keys.SideBySide):
+		if !m.diff.sideBySide && chooseLayout(m.width) != layoutWide {
+			return m, func() tea.Msg {
+				return statusMsg{err: fmt.Errorf("side-by-side needs a terminal at least %d columns wide", wideMinWidth)}
+			}
+		}
+		// Keep the same hunk in view when the rows are rebuilt
+		hunk := m.currentHunk(buildDiffRows(m.diff.diff, m.sideBySide()))
+		m.diff.sideBySide = !m.diff.sideBySide
+		m.diff.scroll = 0
+		for i, row := range buildDiffRows(m.diff.diff, m.sideBySide()) {
+			if row.header && row.hunk == hunk {
+				m.diff.scroll = i
+				break
+			}
+		}
+	case key.Matches(msg, m.keys.Help):
+		m.showHelp = true
+	case key.Matches(msg, m.keys.Quit):
+		m.view = viewFiles
+	}
+	return m, nil
+}
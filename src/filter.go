@@ -0,0 +1,114 @@
+package main
+
+import "strings"
+
+// prFilter holds the list filters toggled in the TUI. The digest applies the
+// same filters so both show the same PRs.
+type prFilter struct {
+	userID      string
+	drafts      bool // show drafts
+	mine        bool // show the user's own PRs
+	notReviewer bool // show PRs the user is not a reviewer of
+	blocking    bool // only PRs with a blocking policy not passing
+	unlinked    bool // only PRs without linked work items
+	query       string
+}
+
+// apply returns the PRs passing every filter. isBlocking and isUnlinked report
+// what is known of a PR's checks and work items, so PRs whose checks or work
+// items have not loaded do not match those filters.
+func (f prFilter) apply(prs []PullRequestInfo, isBlocking, isUnlinked func(prID int) bool) []PullRequestInfo {
+	prs = f.reviewFiltered(prs)
+	if !f.blocking && !f.unlinked && f.query == "" {
+		return prs
+	}
+	var matching []PullRequestInfo
+	for _, pr := range prs {
+		if f.blocking && !isBlocking(pr.id) {
+			continue
+		}
+		if f.unlinked && !isUnlinked(pr.id) {
+			continue
+		}
+		if !matchesQuery(pr, f.query) {
+			continue
+		}
+		matching = append(matching, pr)
+	}
+	return matching
+}
+
+// matchesQuery reports whether a PR matches every term of a filter query.
+// label:name terms need the label, other terms must appear in the title,
+// author or repository.
+func matchesQuery(pr PullRequestInfo, query string) bool {
+	for _, term := range strings.Fields(query) {
+		if name, ok := strings.CutPrefix(term, "label:"); ok {
+			if name != "" && !pr.hasLabel(name) {
+				return false
+			}
+			continue
+		}
+		text := strings.ToLower(pr.title + " " + pr.creator + " " + pr.repository)
+		if !strings.Contains(text, strings.ToLower(term)) {
+			return false
+		}
+	}
+	return true
+}
+
+// reviewFiltered applies the draft, mine and reviewer filters
+func (f prFilter) reviewFiltered(prs []PullRequestInfo) []PullRequestInfo {
+	var filteredPRs []PullRequestInfo
+	seenPRs := make(map[int]bool)
+
+	if f.notReviewer {
+		for _, pullRequest := range prs {
+			if !f.drafts && pullRequest.IsDraft {
+				continue
+			}
+			if !f.mine && pullRequest.creatorID == f.userID {
+				continue
+			}
+			if !seenPRs[pullRequest.id] {
+				filteredPRs = append(filteredPRs, pullRequest)
+				seenPRs[pullRequest.id] = true
+			}
+		}
+		return filteredPRs
+	}
+
+	for _, pullRequest := range prs {
+		if !f.drafts && pullRequest.IsDraft {
+			continue
+		}
+		if pullRequest.creatorID == f.userID && !f.mine {
+			continue
+		}
+		isCurrentUserReviewer := false
+		for _, reviewer := range pullRequest.reviewers {
+			if reviewer.id == f.userID {
+				isCurrentUserReviewer = true
+				break
+			}
+		}
+		if isCurrentUserReviewer && !seenPRs[pullRequest.id] {
+			filteredPRs = append(filteredPRs, pullRequest)
+			seenPRs[pullRequest.id] = true
+		}
+	}
+
+	if f.mine {
+		for _, pullRequest := range prs {
+			if pullRequest.creatorID == f.userID && !pullRequest.IsDraft && !seenPRs[pullRequest.id] {
+				filteredPRs = append(filteredPRs, pullRequest)
+				seenPRs[pullRequest.id] = true
+			}
+			if f.drafts && pullRequest.creatorID == f.userID && pullRequest.IsDraft && !seenPRs[pullRequest.id] {
+				filteredPRs = append(filteredPRs, pullRequest)
+				seenPRs[pullRequest.id] = true
+			}
+		}
+	}
+	return filteredPRs
+}
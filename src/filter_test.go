@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestMatchesQuery(t *testing.T) {
+	pr := PullRequestInfo{id: 1, title: "Fix login redirect", creator: "Ada Lovelace",
+		repository: "web-portal", labels: []string{"Bug", "needs-docs"}}
+	tests := []struct {
+		name  string
+		query string
+		want  bool
+	}{
+		{name: "empty", query: "", want: true},
+		{name: "title", query: "login", want: true},
+		{name: "title ignores case", query: "LOGIN", want: true},
+		{name: "author", query: "lovelace", want: true},
+		{name: "repository", query: "portal", want: true},
+		{name: "no match", query: "logout", want: false},
+		{name: "label", query: "label:bug", want: true},
+		{name: "label ignores case", query: "label:NEEDS-DOCS", want: true},
+		{name: "missing label", query: "label:feature", want: false},
+		{name: "label is not free text", query: "label:login", want: false},
+		{name: "label name is not a term", query: "needs-docs", want: false},
+		{name: "empty label ignored", query: "label:", want: true},
+		{name: "all terms must match", query: "ada label:bug redirect", want: true},
+		{name: "one term missing", query: "ada label:bug logout", want: false},
+		{name: "one label missing", query: "label:bug label:feature", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesQuery(pr, tt.query); got != tt.want {
+				t.Fatalf("matchesQuery(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
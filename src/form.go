@@ -0,0 +1,318 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// fieldKind is the kind of input a form field takes
+type fieldKind int
+
+const (
+	fieldText   fieldKind = iota // free text, edited in place
+	fieldChoice                  // one of a fixed list of options
+	fieldToggle                  // yes or no
+)
+
+// formField is one input of a form
+type formField struct {
+	label   string
+	kind    fieldKind
+	options []string // labels for fieldChoice
+	choice  int
+	on      bool
+	text    []rune
+	cursor  int
+	// multiline text can also be written in $EDITOR
+	multiline bool
+}
+
+func textField(label, value string) formField {
+	return formField{label: label, kind: fieldText, text: []rune(value), cursor: len([]rune(value))}
+}
+
+func choiceField(label string, options []string, choice int) formField {
+	return formField{label: label, kind: fieldChoice, options: options, choice: choice}
+}
+
+func toggleField(label string, on bool) formField {
+	return formField{label: label, kind: fieldToggle, on: on}
+}
+
+func (f formField) value() string {
+	return strings.TrimSpace(string(f.text))
+}
+
+// formKind identifies what submitting a form does
+type formKind int
+
+const (
+	formComplete formKind = iota
+	formAutoComplete
+	formCancelAutoComplete
+	formCreate
+	formPublish
+	formDraft
+	formAbandon
+	formReactivate
+	formEdit
+	formLabels
+)
+
+// formState is a modal form for an action on a PR
+type formState struct {
+	kind   formKind
+	title  string
+	submit string // label of the submit button
+	pr     PullRequestInfo
+	fields []formField
+	focus  int // index of the focused field, len(fields) for the submit button
+	// preview shows what submitting changes, for forms that confirm an edit first
+	preview []string
+	busy    bool
+	err     error
+}
+
+// Form keys are fixed, since letters have to reach the text fields
+var (
+	formNext   = key.NewBinding(key.WithKeys("tab", "down"), key.WithHelp("tab", "next field"))
+	formPrev   = key.NewBinding(key.WithKeys("shift+tab", "up"), key.WithHelp("shift+tab", "previous"))
+	formChange = key.NewBinding(key.WithKeys("left", "right", " "), key.WithHelp("←/→/space", "change"))
+	formSubmit = key.NewBinding(key.WithKeys("ctrl+s"), key.WithHelp("ctrl+s", "submit"))
+	formCancel = key.NewBinding(key.WithKeys("esc", "ctrl+c"), key.WithHelp("esc", "cancel"))
+	formEditor = key.NewBinding(key.WithKeys("ctrl+o"), key.WithHelp("ctrl+o", "open $EDITOR"))
+)
+
+// formDoneMsg reports the outcome of a submitted form
+type formDoneMsg struct {
+	kind formKind
+	pr   PullRequestInfo
+	text string
+	err  error
+}
+
+// openForm shows a form over the PR list
+func (m tuiModel) openForm(form formState) tuiModel {
+	m.form = form
+	m.view = viewForm
+	return m
+}
+
+// updateForm handles keys in a form
+func (m tuiModel) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.form.busy {
+		return m, nil
+	}
+	f := &m.form
+	onSubmit := f.focus == len(f.fields)
+	switch {
+	case key.Matches(msg, formCancel):
+		m.view = viewPRs
+		return m, nil
+	case key.Matches(msg, formSubmit), msg.Type == tea.KeyEnter && onSubmit:
+		return m.submitForm()
+	case key.Matches(msg, formNext), msg.Type == tea.KeyEnter:
+		f.focus = (f.focus + 1) % (len(f.fields) + 1)
+		return m, nil
+	case key.Matches(msg, formPrev):
+		f.focus = (f.focus + len(f.fields)) % (len(f.fields) + 1)
+		return m, nil
+	}
+	if onSubmit {
+		return m, nil
+	}
+	m = m.clearPreview()
+	f = &m.form
+	field := &f.fields[f.focus]
+	switch field.kind {
+	case fieldChoice:
+		switch msg.String() {
+		case "left":
+			field.choice = (field.choice + len(field.options) - 1) % len(field.options)
+		case "right", " ":
+			field.choice = (field.choice + 1) % len(field.options)
+		}
+	case fieldToggle:
+		if key.Matches(msg, formChange) {
+			field.on = !field.on
+		}
+	case fieldText:
+		if field.multiline && key.Matches(msg, formEditor) {
+			return m, editorCmd(f.focus, string(field.text))
+		}
+		field.edit(msg)
+	}
+	return m, nil
+}
+
+// edit applies a key press to a text field
+func (f *formField) edit(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyRunes, tea.KeySpace:
+		runes := msg.Runes
+		if msg.Type == tea.KeySpace {
+			runes = []rune{' '}
+		}
+		f.text = append(f.text[:f.cursor], append(runes, f.text[f.cursor:]...)...)
+		f.cursor += len(runes)
+	case tea.KeyBackspace:
+		if f.cursor > 0 {
+			f.text = append(f.text[:f.cursor-1], f.text[f.cursor:]...)
+			f.cursor--
+		}
+	case tea.KeyDelete:
+		if f.cursor < len(f.text) {
+			f.text = append(f.text[:f.cursor], f.text[f.cursor+1:]...)
+		}
+	case tea.KeyLeft:
+		f.cursor = max(0, f.cursor-1)
+	case tea.KeyRight:
+		f.cursor = min(len(f.text), f.cursor+1)
+	case tea.KeyHome, tea.KeyCtrlA:
+		f.cursor = 0
+	case tea.KeyEnd, tea.KeyCtrlE:
+		f.cursor = len(f.text)
+	case tea.KeyCtrlU:
+		f.text = f.text[f.cursor:]
+		f.cursor = 0
+	}
+}
+
+// submitForm runs the action of the form
+func (m tuiModel) submitForm() (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch m.form.kind {
+	case formComplete:
+		cmd = m.completeCmd()
+	case formAutoComplete, formCancelAutoComplete:
+		cmd = m.autoCompleteCmd()
+	case formCreate:
+		cmd = m.createCmd()
+	case formPublish, formDraft, formAbandon, formReactivate:
+		cmd = m.lifecycleCmd()
+	case formLabels:
+		cmd = m.labelsCmd()
+	case formEdit:
+		if m.form.preview == nil {
+			return m.previewEdit(), nil
+		}
+		cmd = m.editCmd()
+	}
+	if cmd == nil {
+		return m, nil
+	}
+	m.form.busy = true
+	m.form.err = nil
+	return m, cmd
+}
+
+// clearPreview drops a preview that no longer matches the form, so it is
+// shown again before submitting
+func (m tuiModel) clearPreview() tuiModel {
+	if m.form.kind == formEdit && m.form.preview != nil {
+		m.form.preview = nil
+		m.form.submit = "Preview"
+	}
+	return m
+}
+
+// formDone closes a form that succeeded, or shows why it failed
+func (m tuiModel) formDone(msg formDoneMsg) tuiModel {
+	// A created PR has no id until the form succeeds
+	if msg.kind != m.form.kind || (msg.kind != formCreate && msg.pr.id != m.form.pr.id) {
+		return m
+	}
+	m.form.busy = false
+	if msg.err != nil {
+		m.form.err = msg.err
+		return m
+	}
+	m.view = viewPRs
+	m.status = statusMsg{text: msg.text}
+	return m
+}
+
+// renderForm renders the focused form in a centered box
+func (m tuiModel) renderForm(width, height int) string {
+	f := m.form
+	boxWidth := min(width-boxStyle.GetHorizontalFrameSize(), 80)
+	labelWidth := 0
+	for _, field := range f.fields {
+		labelWidth = max(labelWidth, lipgloss.Width(field.label))
+	}
+	valueWidth := max(10, boxWidth-labelWidth-4)
+	lines := []string{titleStyle.UnsetHeight().UnsetMarginBottom().Width(boxWidth).Render(f.title), ""}
+	for i, field := range f.fields {
+		focused := i == f.focus
+		cursor := "  "
+		if focused {
+			cursor = "> "
+		}
+		var value string
+		switch field.kind {
+		case fieldChoice:
+			value = "‹ " + field.options[field.choice] + " ›"
+		case fieldToggle:
+			value = "[ ]"
+			if field.on {
+				value = "[x]"
+			}
+		case fieldText:
+			value = field.render(valueWidth, focused)
+		}
+		label := fmt.Sprintf("%-*s", labelWidth, field.label)
+		if focused {
+			label = selectedStyle.Render(label)
+		}
+		lines = append(lines, cursor+label+"  "+value)
+	}
+	button := "[ " + f.submit + " ]"
+	if f.focus == len(f.fields) {
+		button = selectedStyle.Render("> " + button)
+	} else {
+		button = "  " + button
+	}
+	if len(f.preview) > 0 {
+		lines = append(lines, "")
+		// Keep the button on screen when the preview is long
+		room := max(1, height-boxStyle.GetVerticalFrameSize()-len(lines)-6)
+		for i, line := range f.preview {
+			if i == room-1 && len(f.preview) > room {
+				lines = append(lines, sepStyle.Render(fmt.Sprintf("... %d more lines", len(f.preview)-i)))
+				break
+			}
+			lines = append(lines, lipgloss.NewStyle().MaxWidth(boxWidth).Render(line))
+		}
+	}
+	lines = append(lines, "", button)
+	switch {
+	case f.busy:
+		lines = append(lines, "", sepStyle.Render("Working..."))
+	case f.err != nil:
+		lines = append(lines, "", errorStyle.Render(lipgloss.NewStyle().Width(boxWidth).Render(f.err.Error())))
+	}
+	box := boxStyle.Width(boxWidth + boxStyle.GetHorizontalPadding()).Render(strings.Join(lines, "\n"))
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// render shows the text of a field scrolled so the cursor stays visible,
+// with line breaks shown as ⏎
+func (f formField) render(width int, focused bool) string {
+	text := []rune(strings.ReplaceAll(string(f.text), "\n", "⏎"))
+	start := max(0, f.cursor-width+1)
+	end := min(len(text), start+width)
+	if !focused {
+		return string(text[start:end])
+	}
+	before := string(text[start:f.cursor])
+	at, after := " ", ""
+	if f.cursor < len(text) {
+		at = string(text[f.cursor])
+		after = string(text[f.cursor+1 : end])
+	}
+	return before + lipgloss.NewStyle().Reverse(true).Render(at) + after
+}
@@ -1,5 +1,11 @@
 package main
 
+import (
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops"
+)
+
 // Helper functions to safely dereference pointers
 func derefString(s *string) string {
 	if s != nil {
@@ -21,3 +27,10 @@ func derefBool(b *bool) bool {
 	}
 	return false
 }
+
+func derefTime(t *azuredevops.Time) time.Time {
+	if t != nil {
+		return t.Time
+	}
+	return time.Time{}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+)
+
+// prStatusMode selects which pull requests are listed
+type prStatusMode int
+
+const (
+	statusActive prStatusMode = iota
+	statusCompleted
+	statusAbandoned
+	statusAll
+)
+
+var statusModeNames = []string{"active", "completed", "abandoned", "all"}
+
+func (s prStatusMode) String() string {
+	return statusModeNames[s]
+}
+
+// next returns the mode after s, wrapping around
+func (s prStatusMode) next() prStatusMode {
+	return (s + 1) % prStatusMode(len(statusModeNames))
+}
+
+// lists reports whether the mode lists PRs of the given status
+func (s prStatusMode) lists(status string) bool {
+	switch s {
+	case statusCompleted, statusAbandoned:
+		return status == s.String()
+	case statusAll:
+		return true
+	}
+	return status == "active"
+}
+
+// apiStatuses returns the statuses queried for the mode. All is queried one
+// status at a time so only closed PRs are bound by the time window.
+func (s prStatusMode) apiStatuses() []git.PullRequestStatus {
+	switch s {
+	case statusCompleted:
+		return []git.PullRequestStatus{git.PullRequestStatusValues.Completed}
+	case statusAbandoned:
+		return []git.PullRequestStatus{git.PullRequestStatusValues.Abandoned}
+	case statusAll:
+		return []git.PullRequestStatus{git.PullRequestStatusValues.Active, git.PullRequestStatusValues.Completed, git.PullRequestStatusValues.Abandoned}
+	}
+	return []git.PullRequestStatus{git.PullRequestStatusValues.Active}
+}
+
+// parseStatusMode reads a status mode by name
+func parseStatusMode(name string) (prStatusMode, error) {
+	for i, n := range statusModeNames {
+		if strings.EqualFold(n, name) {
+			return prStatusMode(i), nil
+		}
+	}
+	return statusActive, fmt.Errorf("unknown status %q (available: %s)", name, strings.Join(statusModeNames, ", "))
+}
+
+// historySince returns the start of a time window of days ending now
+func historySince(days int) time.Time {
+	return time.Now().AddDate(0, 0, -days)
+}
+
+// ListPullRequests lists the pull requests of the project in a status mode
+func (s *Session) ListPullRequests(mode prStatusMode, since time.Time) ([]PullRequestInfo, error) {
+	return ListPullRequests(s.ctx, s.gitClient, s.project, mode, since)
+}
+
+// closed reports whether the PR was completed or abandoned
+func (pr PullRequestInfo) closed() bool {
+	return pr.status == "completed" || pr.status == "abandoned"
+}
+
+// closedLabel describes when and by whom a closed PR was completed or abandoned
+func closedLabel(pr PullRequestInfo) string {
+	verb := "Merged"
+	if pr.status == "abandoned" {
+		verb = "Abandoned"
+	}
+	label := verb + " " + pr.closedDate.Local().Format("2006-01-02 15:04")
+	if pr.closedBy != "" {
+		label += " by " + pr.closedBy
+	}
+	return label
+}
+
+// RunList implements `AzurePR list`, printing the pull requests of a status mode
+func RunList(session *Session, args []string, historyDays int) error {
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	status := flags.String("status", "active", "active, completed, abandoned or all")
+	days := flags.Int("days", historyDays, "how many days back to list completed and abandoned PRs")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	mode, err := parseStatusMode(*status)
+	if err != nil {
+		return err
+	}
+	prs, err := session.ListPullRequests(mode, historySince(*days))
+	if err != nil {
+		return err
+	}
+	if len(prs) == 0 {
+		fmt.Printf("No %s pull requests.\n", mode)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTATUS\tREPOSITORY\tTITLE\tAUTHOR\tCLOSED")
+	for _, pr := range prs {
+		closed := ""
+		if pr.status != "active" {
+			closed = closedLabel(pr)
+		}
+		fmt.Fprintf(w, "!%d\t%s\t%s\t%s\t%s\t%s\n", pr.id, pr.status, pr.repository, truncate(pr.title, 60), pr.creator, closed)
+	}
+	return w.Flush()
+}
+
+// prsLoadedMsg carries the pull requests of a status mode
+type prsLoadedMsg struct {
+	mode prStatusMode
+	prs  []PullRequestInfo
+	err  error
+}
+
+// switchStatus lists the pull requests of the next status mode
+func (m tuiModel) switchStatus() (tuiModel, tea.Cmd) {
+	m.statusMode = m.statusMode.next()
+	m.loadingPRs = true
+	session, mode, since := m.session, m.statusMode, historySince(m.historyDays)
+	return m, func() tea.Msg {
+		if session == nil {
+			return prsLoadedMsg{mode: mode, err: errors.New("not connected")}
+		}
+		prs, err := session.ListPullRequests(mode, since)
+		return prsLoadedMsg{mode: mode, prs: prs, err: err}
+	}
+}
+
+// setPRs shows newly listed PRs, loading what the list shows for the ones not seen yet
+func (m tuiModel) setPRs(msg prsLoadedMsg) (tuiModel, tea.Cmd) {
+	if msg.mode != m.statusMode {
+		return m, nil
+	}
+	m.loadingPRs = false
+	if msg.err != nil {
+		m.status = statusMsg{err: msg.err}
+		return m, nil
+	}
+	m.prs = msg.prs
+	m.selected, m.detailScroll = 0, 0
+	m = m.seedSeen()
+	// Checks, conflicts and waiting time only matter while a PR is open
+	var active, unseen []PullRequestInfo
+	for _, pr := range msg.prs {
+		if _, ok := m.workItems[pr.id]; !ok {
+			unseen = append(unseen, pr)
+		}
+		if _, ok := m.checks[pr.id]; !ok && pr.status == "active" {
+			active = append(active, pr)
+		}
+	}
+	return m, tea.Batch(loadChecksCmd(m.session, active), loadConflictsCmd(m.session, active), loadWorkItemsCmd(m.session, unseen), loadActivityCmd(m.session, m.userID, active))
+}
+
+// statusDescription names the listed PRs for messages, e.g. "completed pull requests from the last 14 days"
+func (m tuiModel) statusDescription() string {
+	switch m.statusMode {
+	case statusActive:
+		return "open pull requests"
+	case statusAll:
+		return fmt.Sprintf("open pull requests or ones closed in the last %d days", m.historyDays)
+	}
+	return fmt.Sprintf("%s pull requests from the last %d days", m.statusMode, m.historyDays)
+}
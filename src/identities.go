@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/identity"
+)
+
+// identityInfo is a user or group that can be added as a reviewer
+type identityInfo struct {
+	id          string
+	displayName string
+	email       string
+}
+
+// label shows an identity as "Name <email>"
+func (i identityInfo) label() string {
+	if i.email == "" {
+		return i.displayName
+	}
+	return fmt.Sprintf("%s <%s>", i.displayName, i.email)
+}
+
+// SearchIdentities finds users and groups whose name or email matches query
+func (s *Session) SearchIdentities(query string) ([]identityInfo, error) {
+	client, err := s.identities()
+	if err != nil {
+		return nil, err
+	}
+	filter := "General"
+	membership := identity.QueryMembershipValues.None
+	found, err := client.ReadIdentities(s.ctx, identity.ReadIdentitiesArgs{
+		SearchFilter:    &filter,
+		FilterValue:     &query,
+		QueryMembership: &membership,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var infos []identityInfo
+	if found == nil {
+		return nil, nil
+	}
+	for _, ident := range *found {
+		if ident.Id == nil || (ident.IsActive != nil && !*ident.IsActive) {
+			continue
+		}
+		info := identityInfo{
+			id:          ident.Id.String(),
+			displayName: derefString(ident.ProviderDisplayName),
+		}
+		if ident.CustomDisplayName != nil {
+			info.displayName = *ident.CustomDisplayName
+		}
+		// Properties hold {"Mail": {"$type": ..., "$value": ...}}
+		props, _ := ident.Properties.(map[string]interface{})
+		mail, _ := props["Mail"].(map[string]interface{})
+		info.email, _ = mail["$value"].(string)
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// ResolveIdentity finds the single identity meant by a name or email
+func (s *Session) ResolveIdentity(query string) (identityInfo, error) {
+	matches, err := s.SearchIdentities(query)
+	if err != nil {
+		return identityInfo{}, err
+	}
+	// An exact name or email wins over partial matches
+	for _, match := range matches {
+		if strings.EqualFold(match.email, query) || strings.EqualFold(match.displayName, query) {
+			return match, nil
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return identityInfo{}, fmt.Errorf("no user or group matches %q", query)
+	case 1:
+		return matches[0], nil
+	}
+	labels := make([]string, 0, len(matches))
+	for _, match := range matches {
+		labels = append(labels, match.label())
+	}
+	return identityInfo{}, fmt.Errorf("%q matches several identities: %s", query, strings.Join(labels, ", "))
+}
+
+// fuzzyScore reports whether the letters of pattern appear in order in text,
+// ignoring case, and scores the match higher when letters are consecutive or
+// start words
+func fuzzyScore(pattern, text string) (int, bool) {
+	pat := []rune(strings.ToLower(strings.ReplaceAll(pattern, " ", "")))
+	runes := []rune(strings.ToLower(text))
+	score, p, prev := 0, 0, -2
+	for i, r := range runes {
+		if p == len(pat) {
+			break
+		}
+		if r != pat[p] {
+			continue
+		}
+		score++
+		if i == prev+1 {
+			score += 4
+		}
+		if i == 0 || strings.ContainsRune(" .@<-_", runes[i-1]) {
+			score += 6
+		}
+		prev = i
+		p++
+	}
+	return score, p == len(pat)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		pattern string
+		text    string
+		score   int
+		ok      bool
+	}{
+		{pattern: "ada", text: "Ada Lovelace", score: 17, ok: true},
+		{pattern: "ADA", text: "ada lovelace", score: 17, ok: true},
+		{pattern: "a l", text: "Ada Lovelace", score: 14, ok: true},
+		{pattern: "ada", text: "Linda Adams", score: 7, ok: true},
+		{pattern: "ada", text: "Grace Hopper <ada@navy.mil>", score: 7, ok: true},
+		{pattern: "ada", text: "Bob", ok: false},
+		{pattern: "da", text: "ad", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" in "+tt.text, func(t *testing.T) {
+			score, ok := fuzzyScore(tt.pattern, tt.text)
+			if ok != tt.ok {
+				t.Fatalf("matched = %v, want %v", ok, tt.ok)
+			}
+			if ok && score != tt.score {
+				t.Fatalf("score = %d, want %d", score, tt.score)
+			}
+		})
+	}
+}
+
+func TestReviewerCandidatesOrder(t *testing.T) {
+	pr := reviewedByMe(1, "First")
+	pr.reviewers = append(pr.reviewers, PullrequestReviewer{id: "byron", displayName: "Ada Byron"})
+	other := reviewedByMe(2, "Second")
+	other.creatorID, other.creator = "linda", "Linda Adams"
+	other.reviewers = []PullrequestReviewer{
+		{id: "byron", displayName: "Ada Byron"},
+		{id: "adam", displayName: "Adam Smith"},
+		{id: "bob", displayName: "Bob"},
+	}
+	m := testModel(pr, other)
+	m.reviewers.prID = 1
+	m.reviewers.query = textField("Search", "ada")
+	m.reviewers.found = []identityInfo{
+		{id: "grace", displayName: "Grace Hopper", email: "ada@navy.mil"},
+		{id: "ada", displayName: "Ada Lovelace", email: "ada@example.com"},
+	}
+	var got []string
+	for _, ident := range m.reviewerCandidates() {
+		got = append(got, ident.id)
+	}
+	// Word starts and runs of letters rank first, ties go by name, and the
+	// PR's own reviewers are left out
+	want := []string{"ada", "adam", "grace", "linda"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("candidates = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+)
+
+// prActivity sums up what happened on a PR, as seen by the current user
+type prActivity struct {
+	last     time.Time // latest thread update, or the creation of the PR
+	lastVote time.Time // zero when the user never voted
+	lastPush time.Time
+	// replies counts comments by others after the user's last comment in threads they joined
+	replies int
+	// newComments counts comments by others since the user last commented, voted or pushed
+	newComments int
+	iteration   int // id of the latest push
+	threads     int // comment threads, leaving out system threads
+	comments    int
+}
+
+// PullRequestActivity reads the comment threads of the pull request, where
+// comments, votes and pushes all leave a trace
+func (s *Session) PullRequestActivity(pr PullRequestInfo, userID string) (prActivity, error) {
+	threads, err := s.gitClient.GetThreads(s.ctx, git.GetThreadsArgs{
+		RepositoryId:  &pr.repositoryID,
+		PullRequestId: &pr.id,
+		Project:       &s.project,
+	})
+	if err != nil {
+		return prActivity{}, err
+	}
+	activity := prActivity{last: pr.createdDate}
+	if activity.iteration, err = s.latestIteration(pr); err != nil {
+		return prActivity{}, err
+	}
+	if threads == nil {
+		return activity, nil
+	}
+	var touched time.Time // when the user last did anything on the PR
+	type comment struct {
+		at   time.Time
+		mine bool
+	}
+	var others []time.Time
+	for _, thread := range *threads {
+		for _, at := range []time.Time{derefTime(thread.PublishedDate), derefTime(thread.LastUpdatedDate)} {
+			activity.last = latest(activity.last, at)
+		}
+		published := derefTime(thread.PublishedDate)
+		switch {
+		case isVoteBy(thread, userID):
+			activity.lastVote = latest(activity.lastVote, published)
+			touched = latest(touched, published)
+			continue
+		case threadProperty(thread, "CodeReviewThreadType") == "RefUpdate":
+			activity.lastPush = latest(activity.lastPush, published)
+			if pr.creatorID == userID {
+				touched = latest(touched, published)
+			}
+			continue
+		}
+		if thread.Comments == nil {
+			continue
+		}
+		var comments []comment
+		var myLast time.Time
+		for _, c := range *thread.Comments {
+			if derefBool(c.IsDeleted) || c.CommentType == nil || *c.CommentType != git.CommentTypeValues.Text {
+				continue
+			}
+			mine := c.Author != nil && strings.EqualFold(derefString(c.Author.Id), userID)
+			at := derefTime(c.PublishedDate)
+			comments = append(comments, comment{at: at, mine: mine})
+			if mine {
+				myLast = latest(myLast, at)
+				touched = latest(touched, at)
+			} else {
+				others = append(others, at)
+			}
+		}
+		if len(comments) > 0 {
+			activity.threads++
+			activity.comments += len(comments)
+		}
+		if myLast.IsZero() {
+			continue
+		}
+		for _, c := range comments {
+			if !c.mine && c.at.After(myLast) {
+				activity.replies++
+			}
+		}
+	}
+	for _, at := range others {
+		if at.After(touched) {
+			activity.newComments++
+		}
+	}
+	return activity, nil
+}
+
+// latestIteration returns the id of the latest push to the pull request
+func (s *Session) latestIteration(pr PullRequestInfo) (int, error) {
+	iterations, err := s.gitClient.GetPullRequestIterations(s.ctx, git.GetPullRequestIterationsArgs{
+		RepositoryId:  &pr.repositoryID,
+		PullRequestId: &pr.id,
+		Project:       &s.project,
+	})
+	if err != nil || iterations == nil {
+		return 0, err
+	}
+	id := 0
+	for _, it := range *iterations {
+		id = max(id, derefInt(it.Id))
+	}
+	return id, nil
+}
+
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// attentionReason is why a PR needs the current user, most urgent first
+type attentionReason int
+
+const (
+	reasonRequiredReview attentionReason = iota
+	reasonNewPushes
+	reasonReplies
+	reasonChangesRequested
+	reasonNewComments
+	reasonReadyToComplete
+)
+
+var attentionLabels = []string{"Review required", "New pushes since your vote", "Replies to your comments", "Changes requested", "New comments", "Ready to complete"}
+
+func (r attentionReason) String() string {
+	return attentionLabels[r]
+}
+
+// attention lists why the PR needs the current user, most urgent first
+func (m tuiModel) attention(pr PullRequestInfo) []attentionReason {
+	if pr.closed() {
+		return nil
+	}
+	var reasons []attentionReason
+	activity, loaded := m.activity[pr.id]
+	mine := pr.creatorID == m.userID
+	for _, rev := range pr.reviewers {
+		if rev.id != m.userID || pr.IsDraft {
+			continue
+		}
+		if rev.isRequired && rev.vote == 0 {
+			reasons = append(reasons, reasonRequiredReview)
+		}
+		// Policies resetting votes on push clear the vote, so check the vote history instead
+		if loaded && !activity.lastVote.IsZero() && activity.lastPush.After(activity.lastVote) {
+			reasons = append(reasons, reasonNewPushes)
+		}
+	}
+	if loaded && activity.replies > 0 {
+		reasons = append(reasons, reasonReplies)
+	}
+	if !mine {
+		return reasons
+	}
+	approved, rejected := false, false
+	for _, rev := range pr.reviewers {
+		switch {
+		case rev.vote < 0:
+			rejected = true
+		case rev.vote > 0:
+			approved = true
+		}
+	}
+	if rejected {
+		reasons = append(reasons, reasonChangesRequested)
+	}
+	if loaded && activity.newComments > 0 {
+		reasons = append(reasons, reasonNewComments)
+	}
+	if approved && !rejected && m.completeBlockers(pr) == nil {
+		reasons = append(reasons, reasonReadyToComplete)
+	}
+	return reasons
+}
+
+// inboxPRs returns the PRs needing the current user, most urgent first and
+// the longest waiting first within the same reason
+func (m tuiModel) inboxPRs() []PullRequestInfo {
+	type ranked struct {
+		pr     PullRequestInfo
+		reason attentionReason
+		since  time.Time
+	}
+	var inbox []ranked
+	for _, pr := range m.prs {
+		reasons := m.attention(pr)
+		if len(reasons) == 0 || !matchesQuery(pr, m.filter.value()) {
+			continue
+		}
+		inbox = append(inbox, ranked{pr: pr, reason: reasons[0], since: m.lastActivity(pr)})
+	}
+	sort.SliceStable(inbox, func(i, j int) bool {
+		if inbox[i].reason != inbox[j].reason {
+			return inbox[i].reason < inbox[j].reason
+		}
+		return inbox[i].since.Before(inbox[j].since)
+	})
+	prs := make([]PullRequestInfo, len(inbox))
+	for i, r := range inbox {
+		prs[i] = r.pr
+	}
+	return prs
+}
+
+// renderAttention lists why the PR needs the current user, for the detail pane
+func (m tuiModel) renderAttention(pr PullRequestInfo, width int) []string {
+	reasons := m.attention(pr)
+	if len(reasons) == 0 {
+		return nil
+	}
+	activity := m.activity[pr.id]
+	lines := []string{"Needs your attention:"}
+	for _, reason := range reasons {
+		label := reason.String()
+		switch reason {
+		case reasonReplies:
+			label = fmt.Sprintf("%s (%d)", label, activity.replies)
+		case reasonNewComments:
+			label = fmt.Sprintf("%s (%d)", label, activity.newComments)
+		}
+		lines = append(lines, checkIcon(checkPending)+" "+truncate(label, width-2))
+	}
+	return append(lines, "")
+}
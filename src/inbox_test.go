@@ -0,0 +1,36 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAttentionNewPushesSinceVote(t *testing.T) {
+	voted := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		vote     int
+		required bool
+		activity prActivity
+		want     []attentionReason
+	}{
+		{name: "vote kept after push", vote: 10, activity: prActivity{lastVote: voted, lastPush: voted.Add(time.Hour)}, want: []attentionReason{reasonNewPushes}},
+		{name: "vote reset by push", activity: prActivity{lastVote: voted, lastPush: voted.Add(time.Hour)}, want: []attentionReason{reasonNewPushes}},
+		{name: "required vote reset by push", required: true, activity: prActivity{lastVote: voted, lastPush: voted.Add(time.Hour)},
+			want: []attentionReason{reasonRequiredReview, reasonNewPushes}},
+		{name: "push before vote", vote: 10, activity: prActivity{lastVote: voted, lastPush: voted.Add(-time.Hour)}},
+		{name: "never voted", activity: prActivity{lastPush: voted}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := PullRequestInfo{id: 1, creatorID: "ada", status: "active",
+				reviewers: []PullrequestReviewer{{id: "me", vote: tt.vote, isRequired: tt.required}}}
+			m := testModel(pr)
+			m.activity[1] = tt.activity
+			if got := m.attention(pr); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("attention() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
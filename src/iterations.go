@@ -0,0 +1,278 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+)
+
+// iterationInfo is one push to a pull request
+type iterationInfo struct {
+	id      int
+	author  string
+	created time.Time
+	commits int
+}
+
+// ListIterations returns every push to the pull request, oldest first
+func (s *Session) ListIterations(pr PullRequestInfo) ([]iterationInfo, error) {
+	includeCommits := true
+	iterations, err := s.gitClient.GetPullRequestIterations(s.ctx, git.GetPullRequestIterationsArgs{
+		RepositoryId:   &pr.repositoryID,
+		PullRequestId:  &pr.id,
+		Project:        &s.project,
+		IncludeCommits: &includeCommits,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if iterations == nil {
+		return nil, nil
+	}
+	var infos []iterationInfo
+	for _, it := range *iterations {
+		info := iterationInfo{
+			id:      derefInt(it.Id),
+			created: derefTime(it.CreatedDate),
+		}
+		if it.Author != nil {
+			info.author = derefString(it.Author.DisplayName)
+		}
+		if it.Commits != nil {
+			info.commits = len(*it.Commits)
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// LastVoteTime returns when the user last voted on the pull request, found from
+// the system threads Azure DevOps records for every vote
+func (s *Session) LastVoteTime(pr PullRequestInfo, userID string) (time.Time, bool, error) {
+	threads, err := s.gitClient.GetThreads(s.ctx, git.GetThreadsArgs{
+		RepositoryId:  &pr.repositoryID,
+		PullRequestId: &pr.id,
+		Project:       &s.project,
+	})
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	var last time.Time
+	found := false
+	if threads == nil {
+		return last, false, nil
+	}
+	for _, thread := range *threads {
+		if !isVoteBy(thread, userID) {
+			continue
+		}
+		if published := derefTime(thread.PublishedDate); published.After(last) {
+			last = published
+			found = true
+		}
+	}
+	return last, found, nil
+}
+
+// isVoteBy reports whether a thread is the system thread recording a vote by the user
+func isVoteBy(thread git.GitPullRequestCommentThread, userID string) bool {
+	if threadProperty(thread, "CodeReviewThreadType") != "VoteUpdate" || thread.Identities == nil {
+		return false
+	}
+	identity, ok := (*thread.Identities)[threadProperty(thread, "CodeReviewVotedByIdentity")]
+	return ok && strings.EqualFold(derefString(identity.Id), userID)
+}
+
+// threadProperty reads a string property of a comment thread, which the API
+// returns as {"$type": ..., "$value": ...} objects
+func threadProperty(thread git.GitPullRequestCommentThread, name string) string {
+	props, _ := thread.Properties.(map[string]interface{})
+	prop, _ := props[name].(map[string]interface{})
+	switch v := prop["$value"].(type) {
+	case string:
+		return v
+	case float64:
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+// votedIteration returns the latest iteration pushed before the vote, or 0 if none
+func votedIteration(iterations []iterationInfo, voteTime time.Time) int {
+	voted := 0
+	for _, it := range iterations {
+		if !it.created.After(voteTime) {
+			voted = it.id
+		}
+	}
+	return voted
+}
+
+// iterationsState is the push history of one PR
+type iterationsState struct {
+	pr       PullRequestInfo
+	items    []iterationInfo
+	voted    int // iteration the user last voted on, 0 if never
+	seq      int // number of the load filling the list, see nextLoad
+	selected int
+	loading  bool
+	err      error
+}
+
+type iterationsLoadedMsg struct {
+	seq   int
+	items []iterationInfo
+	voted int
+	err   error
+}
+
+// loadIterationsCmd fetches the push history and the user's last vote
+func loadIterationsCmd(session *Session, pr PullRequestInfo, userID string, seq int) tea.Cmd {
+	return func() tea.Msg {
+		items, err := session.ListIterations(pr)
+		if err != nil {
+			return iterationsLoadedMsg{seq: seq, err: err}
+		}
+		voteTime, ok, err := session.LastVoteTime(pr, userID)
+		voted := 0
+		if ok {
+			voted = votedIteration(items, voteTime)
+		}
+		return iterationsLoadedMsg{seq: seq, items: items, voted: voted, err: err}
+	}
+}
+
+// loadChangesSinceVoteCmd lists what changed between the iteration the user last
+// voted on and the latest one
+func loadChangesSinceVoteCmd(session *Session, pr PullRequestInfo, userID string, seq int) tea.Cmd {
+	return func() tea.Msg {
+		items, err := session.ListIterations(pr)
+		if err != nil {
+			return filesLoadedMsg{seq: seq, err: err}
+		}
+		if len(items) == 0 {
+			return filesLoadedMsg{seq: seq, err: fmt.Errorf("pull request %d has no iterations", pr.id)}
+		}
+		voteTime, ok, err := session.LastVoteTime(pr, userID)
+		if err != nil {
+			return filesLoadedMsg{seq: seq, err: err}
+		}
+		if !ok {
+			return filesLoadedMsg{seq: seq, err: fmt.Errorf("you have not voted on !%d yet", pr.id)}
+		}
+		latest := items[len(items)-1].id
+		voted := votedIteration(items, voteTime)
+		if voted == latest {
+			return filesLoadedMsg{seq: seq, iteration: latest, compareTo: voted}
+		}
+		changes, err := session.ListPullRequestChanges(pr, latest, voted)
+		return filesLoadedMsg{seq: seq, iteration: latest, compareTo: voted, changes: changes, err: err}
+	}
+}
+
+// loadIterationFilesCmd lists the files changed by a single push
+func loadIterationFilesCmd(session *Session, pr PullRequestInfo, iteration, seq int) tea.Cmd {
+	return func() tea.Msg {
+		changes, err := session.ListPullRequestChanges(pr, iteration, iteration-1)
+		return filesLoadedMsg{seq: seq, iteration: iteration, compareTo: iteration - 1, changes: changes, err: err}
+	}
+}
+
+// openIterations switches to the push history of the selected PR
+func (m tuiModel) openIterations() (tuiModel, tea.Cmd) {
+	pr, ok := m.selectedPR()
+	if !ok {
+		return m, nil
+	}
+	m.view = viewIterations
+	m.iterations = iterationsState{pr: pr, loading: true, seq: m.nextLoad()}
+	return m, loadIterationsCmd(m.session, pr, m.userID, m.iterations.seq)
+}
+
+// openChangesSinceVote shows the files changed since the user's last vote on pr
+func (m tuiModel) openChangesSinceVote(pr PullRequestInfo) (tuiModel, tea.Cmd) {
+	m.files = filesState{pr: pr, loading: true, sinceVote: true, back: m.view, seq: m.nextLoad()}
+	m.view = viewFiles
+	return m, loadChangesSinceVoteCmd(m.session, pr, m.userID, m.files.seq)
+}
+
+// updateIterations handles keys in the iterations view
+func (m tuiModel) updateIterations(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		m.iterations.selected = max(0, m.iterations.selected-1)
+	case key.Matches(msg, m.keys.Down):
+		m.iterations.selected = max(0, min(m.iterations.selected+1, len(m.iterations.items)-1))
+	case key.Matches(msg, m.keys.Details):
+		if m.iterations.selected < len(m.iterations.items) {
+			it := m.iterations.items[m.iterations.selected]
+			m.view = viewFiles
+			m.files = filesState{pr: m.iterations.pr, loading: true, back: viewIterations, seq: m.nextLoad()}
+			return m, loadIterationFilesCmd(m.session, m.iterations.pr, it.id, m.files.seq)
+		}
+	case key.Matches(msg, m.keys.SinceVote):
+		return m.openChangesSinceVote(m.iterations.pr)
+	case key.Matches(msg, m.keys.Help):
+		m.showHelp = true
+	case key.Matches(msg, m.keys.Quit):
+		m.view = viewPRs
+	}
+	return m, nil
+}
+
+// renderIterations renders the push history of the selected PR
+func (m tuiModel) renderIterations(width, height int) (string, paneLayout) {
+	frameWidth, frameHeight := boxStyle.GetFrameSize()
+	usableWidth := width - frameWidth
+	header := titleStyle.UnsetHeight().UnsetMarginBottom().Width(usableWidth).Render(fmt.Sprintf("Pushes to !%d", m.iterations.pr.id))
+	lines := []string{header, ""}
+	pane := paneLayout{}
+	switch {
+	case m.iterations.err != nil:
+		lines = append(lines, errorStyle.Render(truncate(m.iterations.err.Error(), usableWidth)))
+	case m.iterations.loading:
+		lines = append(lines, "Loading iterations...")
+	case len(m.iterations.items) == 0:
+		lines = append(lines, "No iterations.")
+	default:
+		authorWidth := max(8, min(30, usableWidth-40))
+		lines = append(lines, sepStyle.Render(fmt.Sprintf("  %-4s %-*s %-16s %7s", "#", authorWidth, "Author", "Pushed", "Commits")))
+		visible := max(1, height-frameHeight-len(lines))
+		first := 0
+		if m.iterations.selected >= visible {
+			first = m.iterations.selected - visible + 1
+		}
+		last := min(len(m.iterations.items), first+visible)
+		pane.first = first
+		pane.content = rect{
+			x: boxStyle.GetMarginLeft() + boxStyle.GetBorderLeftSize() + boxStyle.GetPaddingLeft(),
+			y: boxStyle.GetBorderTopSize() + boxStyle.GetPaddingTop() + len(lines),
+			w: usableWidth,
+			h: last - first,
+		}
+		for i := first; i < last; i++ {
+			it := m.iterations.items[i]
+			cursor := " "
+			if i == m.iterations.selected {
+				cursor = ">"
+			}
+			line := fmt.Sprintf("%s %-4d %-*s %-16s %7d", cursor, it.id, authorWidth, truncate(it.author, authorWidth),
+				it.created.Local().Format("2006-01-02 15:04"), it.commits)
+			if it.id == m.iterations.voted {
+				line += voteStyle.UnsetWidth().Render("  ← your last vote")
+			}
+			if i == m.iterations.selected {
+				line = selectedStyle.Render(line)
+			}
+			lines = append(lines, line)
+		}
+	}
+	box := boxStyle.Width(width - boxStyle.GetHorizontalMargins() - boxStyle.GetHorizontalBorderSize()).Render(strings.Join(lines, "\n"))
+	pane.area = rect{w: lipgloss.Width(box), h: lipgloss.Height(box)}
+	return box, pane
+}
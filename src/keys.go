@@ -0,0 +1,207 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// keyMap holds every key binding used by the TUI
+type keyMap struct {
+	Up                key.Binding
+	Down              key.Binding
+	Details           key.Binding
+	ToggleDrafts      key.Binding
+	ToggleMine        key.Binding
+	ToggleNotReviewer key.Binding
+	ToggleBlocking    key.Binding
+	ToggleUnlinked    key.Binding
+	Inbox             key.Binding
+	MarkRead          key.Binding
+	MarkAllRead       key.Binding
+	OpenBrowser       key.Binding
+	CopyURL           key.Binding
+	CopyRef           key.Binding
+	Files             key.Binding
+	Complete          key.Binding
+	AutoComplete      key.Binding
+	Create            key.Binding
+	Reviewers         key.Binding
+	AddReviewer       key.Binding
+	RemoveReviewer    key.Binding
+	ToggleRequired    key.Binding
+	Edit              key.Binding
+	Labels            key.Binding
+	Filter            key.Binding
+	StatusMode        key.Binding
+	Draft             key.Binding
+	Abandon           key.Binding
+	Iterations        key.Binding
+	WorkItems         key.Binding
+	SinceVote         key.Binding
+	PageUp            key.Binding
+	PageDown          key.Binding
+	NextHunk          key.Binding
+	PrevHunk          key.Binding
+	SideBySide        key.Binding
+	Help              key.Binding
+	Quit              key.Binding
+}
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Up:                key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
+		Down:              key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
+		Details:           key.NewBinding(key.WithKeys("enter", "tab"), key.WithHelp("enter", "details")),
+		ToggleDrafts:      key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "toggle drafts")),
+		ToggleMine:        key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "toggle mine")),
+		ToggleNotReviewer: key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "toggle not reviewer")),
+		ToggleBlocking:    key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "toggle blocked")),
+		ToggleUnlinked:    key.NewBinding(key.WithKeys("l"), key.WithHelp("l", "toggle unlinked")),
+		Inbox:             key.NewBinding(key.WithKeys("I"), key.WithHelp("I", "inbox")),
+		MarkRead:          key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "mark read")),
+		MarkAllRead:       key.NewBinding(key.WithKeys("A"), key.WithHelp("A", "mark all read")),
+		OpenBrowser:       key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "open in browser")),
+		CopyURL:           key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "copy url")),
+		CopyRef:           key.NewBinding(key.WithKeys("Y"), key.WithHelp("Y", "copy !id")),
+		Files:             key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "changed files")),
+		Iterations:        key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "iterations")),
+		WorkItems:         key.NewBinding(key.WithKeys("w"), key.WithHelp("w", "open work item")),
+		SinceVote:         key.NewBinding(key.WithKeys("u"), key.WithHelp("u", "changes since my vote")),
+		Complete:          key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "complete")),
+		AutoComplete:      key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "auto-complete")),
+		Create:            key.NewBinding(key.WithKeys("N"), key.WithHelp("N", "new PR")),
+		Reviewers:         key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "reviewers")),
+		AddReviewer:       key.NewBinding(key.WithKeys("+"), key.WithHelp("+", "add reviewer")),
+		RemoveReviewer:    key.NewBinding(key.WithKeys("x", "delete"), key.WithHelp("x", "remove reviewer")),
+		ToggleRequired:    key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "toggle required")),
+		Edit:              key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit")),
+		Labels:            key.NewBinding(key.WithKeys("L"), key.WithHelp("L", "labels")),
+		Filter:            key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "filter")),
+		StatusMode:        key.NewBinding(key.WithKeys("S"), key.WithHelp("S", "switch status")),
+		Draft:             key.NewBinding(key.WithKeys("D"), key.WithHelp("D", "publish/draft")),
+		Abandon:           key.NewBinding(key.WithKeys("X"), key.WithHelp("X", "abandon/reactivate")),
+		PageUp:            key.NewBinding(key.WithKeys("pgup"), key.WithHelp("pgup", "page up")),
+		PageDown:          key.NewBinding(key.WithKeys("pgdown"), key.WithHelp("pgdn", "page down")),
+		NextHunk:          key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "next hunk")),
+		PrevHunk:          key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "previous hunk")),
+		SideBySide:        key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "side by side")),
+		Help:              key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help")),
+		Quit:              key.NewBinding(key.WithKeys("q", "esc", "ctrl+c"), key.WithHelp("q", "quit")),
+	}
+}
+
+// actions returns the bindings by their config name
+func (k *keyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":                  &k.Up,
+		"down":                &k.Down,
+		"details":             &k.Details,
+		"toggle_drafts":       &k.ToggleDrafts,
+		"toggle_mine":         &k.ToggleMine,
+		"toggle_not_reviewer": &k.ToggleNotReviewer,
+		"toggle_blocking":     &k.ToggleBlocking,
+		"toggle_unlinked":     &k.ToggleUnlinked,
+		"inbox":               &k.Inbox,
+		"mark_read":           &k.MarkRead,
+		"mark_all_read":       &k.MarkAllRead,
+		"open_browser":        &k.OpenBrowser,
+		"copy_url":            &k.CopyURL,
+		"copy_ref":            &k.CopyRef,
+		"files":               &k.Files,
+		"iterations":          &k.Iterations,
+		"work_items":          &k.WorkItems,
+		"since_vote":          &k.SinceVote,
+		"complete":            &k.Complete,
+		"auto_complete":       &k.AutoComplete,
+		"create":              &k.Create,
+		"reviewers":           &k.Reviewers,
+		"add_reviewer":        &k.AddReviewer,
+		"remove_reviewer":     &k.RemoveReviewer,
+		"toggle_required":     &k.ToggleRequired,
+		"edit":                &k.Edit,
+		"labels":              &k.Labels,
+		"filter":              &k.Filter,
+		"status_mode":         &k.StatusMode,
+		"draft":               &k.Draft,
+		"abandon":             &k.Abandon,
+		"page_up":             &k.PageUp,
+		"page_down":           &k.PageDown,
+		"next_hunk":           &k.NextHunk,
+		"prev_hunk":           &k.PrevHunk,
+		"side_by_side":        &k.SideBySide,
+		"help":                &k.Help,
+		"quit":                &k.Quit,
+	}
+}
+
+// newKeyMap builds the key map from the defaults and the user's overrides,
+// returning an error describing every unknown action or conflicting key
+func newKeyMap(overrides map[string][]string) (keyMap, error) {
+	km := defaultKeyMap()
+	actions := km.actions()
+	var errs []error
+	for name, keys := range overrides {
+		binding, ok := actions[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown key action %q", name))
+			continue
+		}
+		if len(keys) == 0 {
+			errs = append(errs, fmt.Errorf("no keys bound to action %q", name))
+			continue
+		}
+		binding.SetKeys(keys...)
+		binding.SetHelp(strings.Join(keys, "/"), binding.Help().Desc)
+	}
+	errs = append(errs, km.conflicts()...)
+	return km, errors.Join(errs...)
+}
+
+// conflicts reports every key bound to more than one action
+func (k *keyMap) conflicts() []error {
+	owners := make(map[string][]string)
+	for name, binding := range k.actions() {
+		for _, keyStr := range binding.Keys() {
+			owners[keyStr] = append(owners[keyStr], name)
+		}
+	}
+	var shared []string
+	for keyStr, names := range owners {
+		if len(names) > 1 {
+			shared = append(shared, keyStr)
+		}
+	}
+	sort.Strings(shared)
+	var errs []error
+	for _, keyStr := range shared {
+		names := owners[keyStr]
+		sort.Strings(names)
+		errs = append(errs, fmt.Errorf("key %q is bound to multiple actions: %s", keyStr, strings.Join(names, ", ")))
+	}
+	return errs
+}
+
+// ShortHelp returns the bindings shown in the footer
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.ToggleDrafts, k.ToggleMine, k.ToggleNotReviewer, k.Help, k.Quit}
+}
+
+// FullHelp returns the bindings shown in the help overlay, grouped in columns
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down, k.Details, k.MarkRead, k.MarkAllRead},
+		{k.Inbox, k.ToggleDrafts, k.ToggleMine, k.ToggleNotReviewer, k.ToggleBlocking, k.ToggleUnlinked, k.Filter, k.StatusMode},
+		{k.OpenBrowser, k.CopyURL, k.CopyRef},
+		{k.Files, k.PageUp, k.PageDown},
+		{k.NextHunk, k.PrevHunk, k.SideBySide},
+		{k.Iterations, k.SinceVote, k.WorkItems},
+		{k.Reviewers, k.AddReviewer, k.RemoveReviewer, k.ToggleRequired},
+		{k.Complete, k.AutoComplete, k.Create},
+		{k.Edit, k.Labels, k.Draft, k.Abandon},
+		{k.Help, k.Quit},
+	}
+}
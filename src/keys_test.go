@@ -0,0 +1,57 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewKeyMap(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string][]string
+		wantErrs  []string
+	}{
+		{name: "defaults"},
+		{name: "override", overrides: map[string][]string{"toggle_drafts": {"ctrl+d", "F2"}}},
+		{name: "swap keys", overrides: map[string][]string{"up": {"j"}, "down": {"k"}}},
+		{name: "unknown action", overrides: map[string][]string{"launch": {"x"}}, wantErrs: []string{`unknown key action "launch"`}},
+		{name: "no keys", overrides: map[string][]string{"help": {}}, wantErrs: []string{`no keys bound to action "help"`}},
+		{
+			name:      "duplicate binding",
+			overrides: map[string][]string{"open_browser": {"d"}},
+			wantErrs:  []string{`key "d" is bound to multiple actions: open_browser, toggle_drafts`},
+		},
+		{
+			name:      "every problem at once",
+			overrides: map[string][]string{"launch": {"x"}, "copy_url": {"o"}},
+			wantErrs:  []string{`unknown key action "launch"`, `key "o" is bound to multiple actions: copy_url, open_browser`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			km, err := newKeyMap(tt.overrides)
+			if len(tt.wantErrs) == 0 && err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.wantErrs {
+				if err == nil || !strings.Contains(err.Error(), want) {
+					t.Errorf("err = %v, want %q", err, want)
+				}
+			}
+			actions := km.actions()
+			for name, keys := range tt.overrides {
+				binding, ok := actions[name]
+				if !ok || len(keys) == 0 {
+					continue
+				}
+				if !reflect.DeepEqual(binding.Keys(), keys) {
+					t.Errorf("%s keys = %q, want %q", name, binding.Keys(), keys)
+				}
+				if help := binding.Help().Key; help != strings.Join(keys, "/") {
+					t.Errorf("%s help = %q", name, help)
+				}
+			}
+		})
+	}
+}
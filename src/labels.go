@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/core"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+)
+
+// labelPalette colors labels without a configured color, picked by name so a
+// label keeps its color between runs
+var labelPalette = []string{"#0e8a16", "#1d76db", "#5319e7", "#b60205", "#d93f0b", "#006b75", "#7057ff", "#9a6700"}
+
+// labelColors holds the colors configured per label, keyed by lower case name
+var labelColors = map[string]string{}
+
+// setLabelColors applies the label colors from the config
+func setLabelColors(colors map[string]string) {
+	labelColors = make(map[string]string, len(colors))
+	for name, color := range colors {
+		labelColors[strings.ToLower(name)] = color
+	}
+}
+
+// labelChip renders a label as a colored chip
+func labelChip(name string) string {
+	color, ok := labelColors[strings.ToLower(name)]
+	if !ok {
+		h := fnv.New32a()
+		h.Write([]byte(strings.ToLower(name)))
+		color = labelPalette[h.Sum32()%uint32(len(labelPalette))]
+	}
+	return lipgloss.NewStyle().Background(lipgloss.Color(color)).Foreground(lipgloss.Color("#ffffff")).Render(" #" + name + " ")
+}
+
+// labelChips renders labels as chips within maxWidth, counting the ones that
+// do not fit, and returns the width they take. A maxWidth of 0 shows every label.
+func labelChips(labels []string, maxWidth int) (string, int) {
+	styled, width := "", 0
+	for i, label := range labels {
+		chip := labelChip(label)
+		more := ""
+		if i < len(labels)-1 {
+			more = fmt.Sprintf(" +%d", len(labels)-i-1)
+		}
+		sep := 0
+		if width > 0 {
+			sep = 1
+		}
+		// Keep room for the count of the labels left out
+		if maxWidth > 0 && width+sep+lipgloss.Width(chip)+len(more) > maxWidth {
+			rest := fmt.Sprintf("+%d", len(labels)-i)
+			if width > 0 {
+				rest = " " + rest
+			}
+			return styled + sepStyle.Render(rest), width + len(rest)
+		}
+		if sep > 0 {
+			styled += " "
+		}
+		styled += chip
+		width += sep + lipgloss.Width(chip)
+	}
+	return styled, width
+}
+
+// hasLabel reports whether the PR has the label, ignoring case
+func (pr PullRequestInfo) hasLabel(name string) bool {
+	for _, label := range pr.labels {
+		if strings.EqualFold(label, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddLabel adds a label to the pull request, creating the label in the project if needed
+func (s *Session) AddLabel(pr PullRequestInfo, name string) error {
+	_, err := s.gitClient.CreatePullRequestLabel(s.ctx, git.CreatePullRequestLabelArgs{
+		Label:         &core.WebApiCreateTagRequestData{Name: &name},
+		RepositoryId:  &pr.repositoryID,
+		PullRequestId: &pr.id,
+		Project:       &s.project,
+	})
+	return err
+}
+
+// RemoveLabel removes a label from the pull request
+func (s *Session) RemoveLabel(pr PullRequestInfo, name string) error {
+	return s.gitClient.DeletePullRequestLabels(s.ctx, git.DeletePullRequestLabelsArgs{
+		RepositoryId:  &pr.repositoryID,
+		PullRequestId: &pr.id,
+		LabelIdOrName: &name,
+		Project:       &s.project,
+	})
+}
+
+// openLabelsForm shows the labels of the selected PR for editing
+func (m tuiModel) openLabelsForm() (tuiModel, tea.Cmd) {
+	pr, ok := m.selectedPR()
+	if !ok {
+		return m, nil
+	}
+	return m.openForm(formState{
+		kind:   formLabels,
+		title:  fmt.Sprintf("Labels of !%d %s (comma separated)", pr.id, pr.title),
+		submit: "Save labels",
+		pr:     pr,
+		fields: []formField{textField("Labels", strings.Join(pr.labels, ", "))},
+	}), nil
+}
+
+// labelsCmd adds the labels that were typed in and removes the ones that were
+// deleted. On a failure the PR carries the labels changed until then, as those
+// are on the server already.
+func (m tuiModel) labelsCmd() tea.Cmd {
+	session, pr := m.session, m.form.pr
+	wanted := splitList(m.form.fields[0].value())
+	return func() tea.Msg {
+		if session == nil {
+			return formDoneMsg{kind: formLabels, pr: pr, err: errors.New("not connected")}
+		}
+		updated := pr
+		updated.labels = append([]string(nil), pr.labels...)
+		var added, removed int
+		for _, label := range pr.labels {
+			if (PullRequestInfo{labels: wanted}).hasLabel(label) {
+				continue
+			}
+			if err := session.RemoveLabel(pr, label); err != nil {
+				return formDoneMsg{kind: formLabels, pr: updated, err: err}
+			}
+			updated.labels = withoutLabel(updated.labels, label)
+			removed++
+		}
+		for _, label := range wanted {
+			if updated.hasLabel(label) {
+				continue
+			}
+			if err := session.AddLabel(pr, label); err != nil {
+				return formDoneMsg{kind: formLabels, pr: updated, err: err}
+			}
+			updated.labels = append(updated.labels, label)
+			added++
+		}
+		return formDoneMsg{kind: formLabels, pr: updated, text: fmt.Sprintf("Added %d and removed %d labels on !%d", added, removed, pr.id)}
+	}
+}
+
+// withoutLabel returns labels without the given one
+func withoutLabel(labels []string, name string) []string {
+	var kept []string
+	for _, label := range labels {
+		if label != name {
+			kept = append(kept, label)
+		}
+	}
+	return kept
+}
+
+// updateFilter handles keys while the filter query is typed
+func (m tuiModel) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.filtering = false
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.filtering = false
+		m.filter = textField("", "")
+	default:
+		m.filter.edit(msg)
+	}
+	m.selected = max(0, min(m.selected, len(m.filteredPRs())-1))
+	return m, nil
+}
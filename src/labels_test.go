@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/core"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+)
+
+// labelGitClient changes labels, failing on the label named fail
+type labelGitClient struct {
+	git.Client
+	fail string
+}
+
+func (c labelGitClient) CreatePullRequestLabel(_ context.Context, args git.CreatePullRequestLabelArgs) (*core.WebApiTagDefinition, error) {
+	if *args.Label.Name == c.fail {
+		return nil, errors.New("cannot add " + c.fail)
+	}
+	return &core.WebApiTagDefinition{Name: args.Label.Name}, nil
+}
+
+func (c labelGitClient) DeletePullRequestLabels(_ context.Context, args git.DeletePullRequestLabelsArgs) error {
+	if *args.LabelIdOrName == c.fail {
+		return errors.New("cannot remove " + c.fail)
+	}
+	return nil
+}
+
+func TestLabelsKeepChangesBeforeFailure(t *testing.T) {
+	tests := []struct {
+		name  string
+		typed string
+		fail  string
+		want  []string
+	}{
+		{name: "all applied", typed: "bug, ui", want: []string{"bug", "ui"}},
+		{name: "add fails", typed: "ui, new", fail: "new", want: []string{"ui"}},
+		{name: "remove fails", typed: "ui", fail: "bug", want: []string{"bug"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := reviewedByMe(1, "First")
+			pr.labels = []string{"old", "bug"}
+			m := testModel(pr)
+			m.session = &Session{ctx: context.Background(), gitClient: labelGitClient{fail: tt.fail}}
+			m, _ = m.openLabelsForm()
+			m.form.fields[0] = textField("Labels", tt.typed)
+			m.form.busy = true
+			m = update(t, m, m.labelsCmd()())
+			if !reflect.DeepEqual(m.prs[0].labels, tt.want) {
+				t.Fatalf("labels = %q, want %q", m.prs[0].labels, tt.want)
+			}
+			if tt.fail != "" && !reflect.DeepEqual(m.form.pr.labels, tt.want) {
+				t.Fatalf("form labels = %q, want %q for a retry", m.form.pr.labels, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import "strings"
+
+// layoutMode describes how the PR list and the detail pane share the screen
+type layoutMode int
+
+const (
+	layoutCompact layoutMode = iota // single pane, list or detail
+	layoutStacked                   // list above detail
+	layoutWide                      // list and detail side by side
+)
+
+const (
+	wideMinWidth    = 140
+	stackedMinWidth = 80
+	footerHeight    = 3
+)
+
+// chooseLayout picks a layout mode for the given terminal width
+func chooseLayout(width int) layoutMode {
+	switch {
+	case width >= wideMinWidth:
+		return layoutWide
+	case width >= stackedMinWidth:
+		return layoutStacked
+	default:
+		return layoutCompact
+	}
+}
+
+// paneSizes returns the outer width of the list and detail panes for a layout
+func paneSizes(mode layoutMode, width int) (listWidth, detailWidth int) {
+	switch mode {
+	case layoutWide:
+		listWidth = width * 2 / 5
+		return listWidth, width - listWidth
+	case layoutStacked:
+		return width * 3 / 4, width * 3 / 4
+	default:
+		return width, width
+	}
+}
+
+// reviewerColumns holds the widths of the reviewer table columns, a zero width means the column is hidden
+type reviewerColumns struct {
+	name     int
+	required int
+	vote     int
+	id       int
+}
+
+const (
+	nameColMin     = 8
+	nameColMax     = 30
+	requiredColMax = 9
+	requiredColMin = 4
+	voteColWidth   = 10
+	idColMax       = 24
+	idColMin       = 13
+)
+
+// layoutReviewerColumns fits the reviewer table into the given width, shrinking
+// and dropping columns in order of least importance (ID, Required, Name)
+func layoutReviewerColumns(width int) reviewerColumns {
+	cols := reviewerColumns{name: 20, required: requiredColMax, vote: voteColWidth, id: idColMax}
+	// Borders: one before each column plus the closing one
+	total := func() int {
+		sum, count := 0, 0
+		for _, w := range []int{cols.name, cols.required, cols.vote, cols.id} {
+			if w > 0 {
+				sum += w
+				count++
+			}
+		}
+		return sum + count + 1
+	}
+	if extra := width - total(); extra > 0 {
+		cols.name += min(extra, nameColMax-cols.name)
+		return cols
+	}
+	if over := total() - width; over > 0 {
+		cols.id = max(idColMin, cols.id-over)
+	}
+	if total() > width {
+		cols.id = 0
+	}
+	if extra := width - total(); extra > 0 {
+		cols.name += min(extra, nameColMax-cols.name)
+		return cols
+	}
+	if over := total() - width; over > 0 {
+		cols.required = max(requiredColMin, cols.required-over)
+	}
+	if over := total() - width; over > 0 {
+		cols.name = max(nameColMin, cols.name-over)
+	}
+	if total() > width {
+		cols.required = 0
+	}
+	return cols
+}
+
+// widths returns the visible column widths in display order
+func (c reviewerColumns) widths() []int {
+	var out []int
+	for _, w := range []int{c.name, c.required, c.vote, c.id} {
+		if w > 0 {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// border renders a horizontal table border using the given corner and junction runes
+func (c reviewerColumns) border(left, mid, right string) string {
+	parts := make([]string, 0, 4)
+	for _, w := range c.widths() {
+		parts = append(parts, strings.Repeat("─", w))
+	}
+	return left + strings.Join(parts, mid) + right
+}
+
+// truncate shortens s to at most n runes, marking the cut with "..."
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if n <= 0 {
+		return ""
+	}
+	if len(r) <= n {
+		return s
+	}
+	if n <= 3 {
+		return string(r[:n])
+	}
+	return string(r[:n-3]) + "..."
+}
@@ -0,0 +1,80 @@
+package main
+
+import "testing"
+
+func TestChooseLayoutAndPaneSizes(t *testing.T) {
+	tests := []struct {
+		width        int
+		mode         layoutMode
+		list, detail int
+	}{
+		{width: 0, mode: layoutCompact, list: 0, detail: 0},
+		{width: 40, mode: layoutCompact, list: 40, detail: 40},
+		{width: stackedMinWidth - 1, mode: layoutCompact, list: 79, detail: 79},
+		{width: stackedMinWidth, mode: layoutStacked, list: 60, detail: 60},
+		{width: wideMinWidth - 1, mode: layoutStacked, list: 104, detail: 104},
+		{width: wideMinWidth, mode: layoutWide, list: 56, detail: 84},
+		{width: 201, mode: layoutWide, list: 80, detail: 121},
+	}
+	for _, tt := range tests {
+		mode := chooseLayout(tt.width)
+		if mode != tt.mode {
+			t.Errorf("chooseLayout(%d) = %d, want %d", tt.width, mode, tt.mode)
+		}
+		if list, detail := paneSizes(mode, tt.width); list != tt.list || detail != tt.detail {
+			t.Errorf("paneSizes(%d) = %d, %d, want %d, %d", tt.width, list, detail, tt.list, tt.detail)
+		}
+	}
+}
+
+func TestLayoutReviewerColumns(t *testing.T) {
+	tests := []struct {
+		width int
+		want  reviewerColumns
+	}{
+		{width: 120, want: reviewerColumns{name: 30, required: 9, vote: 10, id: 24}},
+		{width: 78, want: reviewerColumns{name: 30, required: 9, vote: 10, id: 24}},
+		{width: 68, want: reviewerColumns{name: 20, required: 9, vote: 10, id: 24}},
+		{width: 60, want: reviewerColumns{name: 20, required: 9, vote: 10, id: 16}},
+		{width: 57, want: reviewerColumns{name: 20, required: 9, vote: 10, id: 13}},
+		// The ID goes first, and the name takes back the room it leaves
+		{width: 56, want: reviewerColumns{name: 30, required: 9, vote: 10}},
+		{width: 40, want: reviewerColumns{name: 20, required: 6, vote: 10}},
+		{width: 30, want: reviewerColumns{name: 12, required: 4, vote: 10}},
+		// Then Required, leaving the shortest name and the vote
+		{width: 25, want: reviewerColumns{name: 8, vote: 10}},
+		{width: 5, want: reviewerColumns{name: 8, vote: 10}},
+		{width: 0, want: reviewerColumns{name: 8, vote: 10}},
+	}
+	for _, tt := range tests {
+		cols := layoutReviewerColumns(tt.width)
+		if cols != tt.want {
+			t.Errorf("layoutReviewerColumns(%d) = %+v, want %+v", tt.width, cols, tt.want)
+		}
+		if border := len([]rune(cols.border("┌", "┬", "┐"))); tt.width >= 21 && border > tt.width {
+			t.Errorf("layoutReviewerColumns(%d) is %d wide", tt.width, border)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{s: "hello", n: -1, want: ""},
+		{s: "hello", n: 0, want: ""},
+		{s: "hello", n: 2, want: "he"},
+		{s: "hello", n: 3, want: "hel"},
+		{s: "hello", n: 4, want: "h..."},
+		{s: "hello", n: 5, want: "hello"},
+		{s: "hello", n: 50, want: "hello"},
+		{s: "héllo wörld", n: 8, want: "héllo..."},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.s, tt.n); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
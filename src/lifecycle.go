@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+)
+
+// SetDraft publishes a draft pull request, or turns a published one back into a draft
+func (s *Session) SetDraft(pr PullRequestInfo, draft bool) error {
+	_, err := s.gitClient.UpdatePullRequest(s.ctx, git.UpdatePullRequestArgs{
+		GitPullRequestToUpdate: &git.GitPullRequest{IsDraft: &draft},
+		RepositoryId:           &pr.repositoryID,
+		PullRequestId:          &pr.id,
+		Project:                &s.project,
+	})
+	return err
+}
+
+// AbandonPullRequest abandons the pull request, first leaving comment on it
+// when one is given
+func (s *Session) AbandonPullRequest(pr PullRequestInfo, comment string) error {
+	if comment != "" {
+		commentType := git.CommentTypeValues.Text
+		threadStatus := git.CommentThreadStatusValues.Closed
+		_, err := s.gitClient.CreateThread(s.ctx, git.CreateThreadArgs{
+			CommentThread: &git.GitPullRequestCommentThread{
+				Comments: &[]git.Comment{{Content: &comment, CommentType: &commentType}},
+				Status:   &threadStatus,
+			},
+			RepositoryId:  &pr.repositoryID,
+			PullRequestId: &pr.id,
+			Project:       &s.project,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return s.setStatus(pr, git.PullRequestStatusValues.Abandoned)
+}
+
+// ReactivatePullRequest makes an abandoned pull request active again
+func (s *Session) ReactivatePullRequest(pr PullRequestInfo) error {
+	return s.setStatus(pr, git.PullRequestStatusValues.Active)
+}
+
+func (s *Session) setStatus(pr PullRequestInfo, status git.PullRequestStatus) error {
+	_, err := s.gitClient.UpdatePullRequest(s.ctx, git.UpdatePullRequestArgs{
+		GitPullRequestToUpdate: &git.GitPullRequest{Status: &status},
+		RepositoryId:           &pr.repositoryID,
+		PullRequestId:          &pr.id,
+		Project:                &s.project,
+	})
+	return err
+}
+
+// openDraftForm asks to publish the selected draft, or to turn the selected PR back into a draft
+func (m tuiModel) openDraftForm() (tuiModel, tea.Cmd) {
+	pr, ok := m.selectedPR()
+	if !ok {
+		return m, nil
+	}
+	if pr.closed() {
+		return m, func() tea.Msg { return statusMsg{err: m.completeBlockers(pr)} }
+	}
+	if pr.IsDraft {
+		return m.openForm(formState{
+			kind:   formPublish,
+			title:  fmt.Sprintf("Publish !%d %s? Reviewers will be notified.", pr.id, pr.title),
+			submit: "Publish",
+			pr:     pr,
+		}), nil
+	}
+	return m.openForm(formState{
+		kind:   formDraft,
+		title:  fmt.Sprintf("Turn !%d %s back into a draft?", pr.id, pr.title),
+		submit: "Convert to draft",
+		pr:     pr,
+	}), nil
+}
+
+// openAbandonForm asks to abandon the selected PR, or to reactivate it when it is abandoned
+func (m tuiModel) openAbandonForm() (tuiModel, tea.Cmd) {
+	pr, ok := m.selectedPR()
+	if !ok {
+		return m, nil
+	}
+	if pr.status == "completed" {
+		return m, func() tea.Msg { return statusMsg{err: fmt.Errorf("!%d is already completed", pr.id)} }
+	}
+	if pr.status == "abandoned" {
+		return m.openForm(formState{
+			kind:   formReactivate,
+			title:  fmt.Sprintf("Reactivate !%d %s?", pr.id, pr.title),
+			submit: "Reactivate",
+			pr:     pr,
+		}), nil
+	}
+	return m.openForm(formState{
+		kind:   formAbandon,
+		title:  fmt.Sprintf("Abandon !%d %s?", pr.id, pr.title),
+		submit: "Abandon",
+		pr:     pr,
+		fields: []formField{textField("Comment (optional)", "")},
+	}), nil
+}
+
+// lifecycleCmd publishes, drafts, abandons or reactivates the PR of the open form
+func (m tuiModel) lifecycleCmd() tea.Cmd {
+	session, pr, kind := m.session, m.form.pr, m.form.kind
+	comment := ""
+	if kind == formAbandon {
+		comment = m.form.fields[0].value()
+	}
+	return func() tea.Msg {
+		if session == nil {
+			return formDoneMsg{kind: kind, pr: pr, err: errors.New("not connected")}
+		}
+		var err error
+		var text string
+		switch kind {
+		case formPublish:
+			err = session.SetDraft(pr, false)
+			pr.IsDraft, text = false, fmt.Sprintf("Published !%d", pr.id)
+		case formDraft:
+			err = session.SetDraft(pr, true)
+			pr.IsDraft, text = true, fmt.Sprintf("!%d is a draft again", pr.id)
+		case formAbandon:
+			err = session.AbandonPullRequest(pr, comment)
+			pr.status, pr.closedDate, text = "abandoned", time.Now(), fmt.Sprintf("Abandoned !%d", pr.id)
+		case formReactivate:
+			err = session.ReactivatePullRequest(pr)
+			pr.status, pr.closedDate, pr.closedBy, text = "active", time.Time{}, "", fmt.Sprintf("Reactivated !%d", pr.id)
+		}
+		if err != nil {
+			return formDoneMsg{kind: kind, pr: pr, err: err}
+		}
+		return formDoneMsg{kind: kind, pr: pr, text: text}
+	}
+}
@@ -2,9 +2,11 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/microsoft/azure-devops-go-api/azuredevops"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
@@ -18,17 +20,46 @@ type PullrequestReviewer struct {
 }
 
 type PullRequestInfo struct {
-	id        int
-	title     string
-	creator   string
-	creatorID string
-	IsDraft   bool
-	reviewers []PullrequestReviewer
+	id           int
+	title        string
+	creator      string
+	creatorID    string
+	IsDraft      bool
+	reviewers    []PullrequestReviewer
+	repository   string
+	repositoryID string
+	projectID    string
+	url          string
+	status       string // active, abandoned or completed
+	description  string // may be cut short in PR lists, see Session.GetPullRequest
+	sourceBranch string
+	targetBranch string
+	labels       []string
+	createdDate  time.Time
+	closedDate   time.Time // when the PR was completed or abandoned
+	closedBy     string
+	mergeStatus  string // conflicts, failure, rejectedByPolicy, succeeded, queued or notSet
+	mergeFailure string
+	// lastMergeSourceCommit is the source commit the PR was last merged from, needed to complete it
+	lastMergeSourceCommit string
+	autoCompleteSetBy     string // who set auto-complete, empty when it is off
+	autoCompleteSetByID   string
 }
 
 func main() {
 	args := os.Args
-	if len(args) > 1 && args[1] == "reset" {
+	command := ""
+	if len(args) > 1 {
+		command = args[1]
+	}
+	switch command {
+	case "", "reset", "create", "list", "watch", "digest":
+	default:
+		fmt.Println("Unknown command:", command)
+		fmt.Println("Usage: AzurePR [reset | create [flags] | list [flags] | watch [flags] | digest [flags]]")
+		return
+	}
+	if command == "reset" {
 		if err := DeletePAT(); err != nil {
 			fmt.Println("Error deleting PAT:", err)
 		}
@@ -40,6 +71,26 @@ func main() {
 		}
 		fmt.Println("PAT, organization, and project have been reset. Please enter new values when prompted.")
 	}
+	config, err := LoadConfig()
+	if err != nil {
+		fmt.Println("Error loading config:", err)
+		return
+	}
+	if err := SetupTheme(config); err != nil {
+		fmt.Println("Error loading theme:", err)
+		return
+	}
+	keys, err := newKeyMap(config.Keys)
+	if err != nil {
+		fmt.Println("Invalid key bindings in config:")
+		fmt.Println(err)
+		return
+	}
+	ages, err := newAgePolicy(config.Staleness)
+	if err != nil {
+		fmt.Println("Invalid staleness settings in config:", err)
+		return
+	}
 	baseAddress := "https://dev.azure.com"
 	organization, err := EnsureOrganization()
 	if err != nil {
@@ -68,33 +119,53 @@ func main() {
 		gitClient, err := git.NewClient(ctx, connection)
 		if err != nil {
 			if strings.Contains(err.Error(), "401") {
-				fmt.Println("PAT is invalid or expired. Please enter a new PAT.")
-				PAT, err = PromptPAT()
-				if err != nil {
-					fmt.Println("Error reading PAT:", err)
+				if PAT, err = renewPAT(); err != nil {
+					fmt.Println(err)
 					return
 				}
-				if err := SetPAT(PAT); err != nil {
-					fmt.Println("Error saving PAT:", err)
+				tryCount++
+				continue
+			}
+			panic(err)
+		}
+
+		if command != "" && command != "reset" {
+			session := NewSession(ctx, connection, gitClient, organization, project)
+			switch command {
+			case "create":
+				err = RunCreate(session, args[2:])
+			case "list":
+				err = RunList(session, args[2:], config.historyDays())
+			case "watch", "digest":
+				var userID string
+				if userID, err = GetCurrentUserID(PAT, organization); err != nil {
+					break
+				}
+				if command == "watch" {
+					err = RunWatch(session, userID, args[2:], config.Watch)
+				} else {
+					err = RunDigest(session, userID, args[2:], ages)
+				}
+			}
+			if err != nil && strings.Contains(err.Error(), "401") {
+				if PAT, err = renewPAT(); err != nil {
+					fmt.Println(err)
 					return
 				}
 				tryCount++
 				continue
 			}
-			panic(err)
+			if err != nil && err != flag.ErrHelp {
+				fmt.Printf("Error running %s: %v\n", command, err)
+			}
+			return
 		}
 
 		pullRequests, err := ListOpenPullRequests(ctx, gitClient, project)
 		if err != nil {
 			if strings.Contains(err.Error(), "401") {
-				fmt.Println("PAT is invalid or expired. Please enter a new PAT.")
-				PAT, err = PromptPAT()
-				if err != nil {
-					fmt.Println("Error reading PAT:", err)
-					return
-				}
-				if err := SetPAT(PAT); err != nil {
-					fmt.Println("Error saving PAT:", err)
+				if PAT, err = renewPAT(); err != nil {
+					fmt.Println(err)
 					return
 				}
 				tryCount++
@@ -108,8 +179,14 @@ func main() {
 			RunTUIWithError(pullRequests, err.Error())
 			return
 		}
+		seen, err := LoadSeen(organization, project)
+		if err != nil {
+			fmt.Println("Error loading read state:", err)
+			return
+		}
 		// Pass all PRs to the TUI, let it handle filtering
-		RunTUI(pullRequests, userID)
+		session := NewSession(ctx, connection, gitClient, organization, project)
+		RunTUI(pullRequests, userID, keys, session, config.historyDays(), ages, seen, config.Watch)
 		return
 	}
 }
@@ -128,6 +205,13 @@ func printPullRequest(pr PullRequestInfo) {
 
 // ListOpenPullRequests lists all open pull requests in all repositories in the specified project
 func ListOpenPullRequests(ctx context.Context, gitClient git.Client, project string) ([]PullRequestInfo, error) {
+	return ListPullRequests(ctx, gitClient, project, statusActive, time.Time{})
+}
+
+// ListPullRequests lists the pull requests of a status mode in all repositories
+// in the specified project. Completed and abandoned PRs are only listed when they
+// were closed after since, so the query stays fast on busy projects.
+func ListPullRequests(ctx context.Context, gitClient git.Client, project string, mode prStatusMode, since time.Time) ([]PullRequestInfo, error) {
 	repos, err := gitClient.GetRepositories(ctx, git.GetRepositoriesArgs{
 		Project: &project,
 	})
@@ -138,24 +222,82 @@ func ListOpenPullRequests(ctx context.Context, gitClient git.Client, project str
 		return nil, nil
 	}
 	var allPRs []PullRequestInfo
-	statusActive := git.PullRequestStatus("active")
-	for _, repo := range *repos {
-		repoIdStr := repo.Id.String()
-		prs, err := gitClient.GetPullRequests(ctx, git.GetPullRequestsArgs{
-			RepositoryId: &repoIdStr,
-			SearchCriteria: &git.GitPullRequestSearchCriteria{
-				Status: &statusActive,
-			},
-			Project: &project,
-		})
+	for _, status := range mode.apiStatuses() {
+		for _, repo := range *repos {
+			prs, err := listRepoPullRequests(ctx, gitClient, project, repo.Id.String(), status, since)
+			if err != nil {
+				return nil, err
+			}
+			allPRs = append(allPRs, prs...)
+		}
+	}
+	return allPRs, nil
+}
+
+// historyPageSize is how many closed PRs are fetched per request
+const historyPageSize = 100
+
+// closedPRLookback is how long before the history window a PR closed inside it
+// may have been created. Older ones are not listed, as finding them would mean
+// paging through every closed PR of the repository.
+const closedPRLookback = 180 * 24 * time.Hour
+
+// listRepoPullRequests lists the pull requests of one status in a repository.
+// Closed PRs come newest created first, not by when they closed, so paging
+// stops at the first page created entirely before since minus closedPRLookback.
+func listRepoPullRequests(ctx context.Context, gitClient git.Client, project, repoID string, status git.PullRequestStatus, since time.Time) ([]PullRequestInfo, error) {
+	args := git.GetPullRequestsArgs{
+		RepositoryId:   &repoID,
+		SearchCriteria: &git.GitPullRequestSearchCriteria{Status: &status},
+		Project:        &project,
+	}
+	if status == git.PullRequestStatusValues.Active {
+		prs, err := gitClient.GetPullRequests(ctx, args)
+		if err != nil || prs == nil {
+			return nil, err
+		}
+		infos := make([]PullRequestInfo, 0, len(*prs))
+		for _, pr := range *prs {
+			infos = append(infos, createPullRequestInfo(&pr))
+		}
+		return infos, nil
+	}
+	var infos []PullRequestInfo
+	top := historyPageSize
+	for skip := 0; ; skip += top {
+		args.Top, args.Skip = &top, &skip
+		prs, err := gitClient.GetPullRequests(ctx, args)
 		if err != nil {
 			return nil, err
 		}
-		if prs != nil {
-			for _, pr := range *prs {
-				allPRs = append(allPRs, createPullRequestInfo(&pr))
+		if prs == nil {
+			return infos, nil
+		}
+		recent := false
+		for _, pr := range *prs {
+			info := createPullRequestInfo(&pr)
+			if !info.closedDate.Before(since) {
+				infos = append(infos, info)
+			}
+			if !info.createdDate.Before(since.Add(-closedPRLookback)) {
+				recent = true
 			}
 		}
+		if len(*prs) < top || !recent {
+			return infos, nil
+		}
 	}
-	return allPRs, nil
+}
+
+// renewPAT asks for a new PAT after the stored one was rejected and saves it
+func renewPAT() (string, error) {
+	fmt.Println("PAT is invalid or expired. Please enter a new PAT.")
+	PAT, err := PromptPAT()
+	if err != nil {
+		return "", fmt.Errorf("Error reading PAT: %w", err)
+	}
+	if err := SetPAT(PAT); err != nil {
+		return "", fmt.Errorf("Error saving PAT: %w", err)
+	}
+	return PAT, nil
 }
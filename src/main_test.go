@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/webapi"
+)
+
+// pagedGitClient serves GetPullRequests from a fixed list, newest created first
+// like Azure DevOps does
+type pagedGitClient struct {
+	git.Client
+	prs   []git.GitPullRequest
+	pages int
+}
+
+func (c *pagedGitClient) GetPullRequests(_ context.Context, args git.GetPullRequestsArgs) (*[]git.GitPullRequest, error) {
+	c.pages++
+	start := min(*args.Skip, len(c.prs))
+	page := c.prs[start:min(start+*args.Top, len(c.prs))]
+	return &page, nil
+}
+
+func closedPR(id int, created, closed time.Time) git.GitPullRequest {
+	return git.GitPullRequest{
+		PullRequestId: &id,
+		CreatedBy:     &webapi.IdentityRef{},
+		CreationDate:  &azuredevops.Time{Time: created},
+		ClosedDate:    &azuredevops.Time{Time: closed},
+	}
+}
+
+func TestListRepoPullRequestsPagesByCreation(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	since := now.AddDate(0, 0, -14)
+	days := func(n int) time.Time { return now.AddDate(0, 0, -n) }
+
+	var prs []git.GitPullRequest
+	id := 1000
+	// A full page of PRs created and closed long before the window
+	for range historyPageSize {
+		prs = append(prs, closedPR(id, days(20), days(19)))
+		id--
+	}
+	// A long-lived PR closed inside the window sits on the second page
+	prs = append(prs, closedPR(1, days(100), days(2)))
+	// A page of PRs created before the lookback ends the paging
+	for range 2 * historyPageSize {
+		prs = append(prs, closedPR(id, days(300), days(299)))
+		id--
+	}
+	prs = append(prs, closedPR(2, days(400), days(1)))
+
+	client := &pagedGitClient{prs: prs}
+	infos, err := listRepoPullRequests(context.Background(), client, "project", "repo", git.PullRequestStatusValues.Completed, since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	for _, info := range infos {
+		ids = append(ids, info.id)
+	}
+	if want := []int{1}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("listed %v, want %v", ids, want)
+	}
+	if client.pages != 3 {
+		t.Fatalf("fetched %d pages, want 3", client.pages)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+)
+
+// pullRequestConflictsLocation is the REST resource listing the conflicts of a
+// pull request merge, which the git client does not wrap
+var pullRequestConflictsLocation = uuid.MustParse("d840fb74-bbef-42d3-b250-564604c054a4")
+
+// mergeBadge returns the list badge for a PR that cannot merge cleanly, or "" when it can
+func mergeBadge(status string) string {
+	switch git.PullRequestAsyncStatus(status) {
+	case git.PullRequestAsyncStatusValues.Conflicts:
+		return "[Conflicts]"
+	case git.PullRequestAsyncStatusValues.Failure:
+		return "[Merge failed]"
+	case git.PullRequestAsyncStatusValues.RejectedByPolicy:
+		return "[Rejected by policy]"
+	}
+	return ""
+}
+
+// mergeLabel describes the merge status of a PR for the detail pane
+func mergeLabel(pr PullRequestInfo) (checkState, string) {
+	switch git.PullRequestAsyncStatus(pr.mergeStatus) {
+	case git.PullRequestAsyncStatusValues.Succeeded:
+		return checkPassed, "Merges cleanly"
+	case git.PullRequestAsyncStatusValues.Queued:
+		return checkPending, "Merge check queued"
+	case git.PullRequestAsyncStatusValues.Conflicts:
+		return checkFailed, "Merge conflicts"
+	case git.PullRequestAsyncStatusValues.Failure:
+		if pr.mergeFailure != "" {
+			return checkFailed, "Merge failed: " + pr.mergeFailure
+		}
+		return checkFailed, "Merge failed"
+	case git.PullRequestAsyncStatusValues.RejectedByPolicy:
+		return checkFailed, "Merge rejected by policy"
+	}
+	return checkNone, "Merge not checked yet"
+}
+
+// ListConflicts returns the paths that conflict when merging the pull request
+func (s *Session) ListConflicts(pr PullRequestInfo) ([]string, error) {
+	client, err := s.connection.GetClientByResourceAreaId(s.ctx, git.ResourceAreaId)
+	if err != nil {
+		return nil, err
+	}
+	routeValues := map[string]string{
+		"project":       s.project,
+		"repositoryId":  pr.repositoryID,
+		"pullRequestId": fmt.Sprint(pr.id),
+	}
+	resp, err := client.Send(s.ctx, http.MethodGet, pullRequestConflictsLocation, "5.1-preview.1", routeValues, nil, nil, "", "application/json", nil)
+	if err != nil {
+		return nil, err
+	}
+	var conflicts []git.GitConflict
+	if err := client.UnmarshalCollectionBody(resp, &conflicts); err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, conflict := range conflicts {
+		if path := derefString(conflict.ConflictPath); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// prConflicts holds the conflicting paths of one PR once they have been loaded
+type prConflicts struct {
+	paths []string
+	err   error
+}
+
+type conflictsLoadedMsg struct {
+	prID  int
+	paths []string
+	err   error
+}
+
+// loadConflictsCmd fetches the conflicting files of every PR that has conflicts
+func loadConflictsCmd(session *Session, prs []PullRequestInfo) tea.Cmd {
+	if session == nil {
+		return nil
+	}
+	var cmds []tea.Cmd
+	for _, pr := range prs {
+		if git.PullRequestAsyncStatus(pr.mergeStatus) != git.PullRequestAsyncStatusValues.Conflicts {
+			continue
+		}
+		cmds = append(cmds, func() tea.Msg {
+			checksLimit <- struct{}{}
+			defer func() { <-checksLimit }()
+			paths, err := session.ListConflicts(pr)
+			return conflictsLoadedMsg{prID: pr.id, paths: paths, err: err}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// renderMerge renders the merge status and any conflicting files for the detail pane
+func (m tuiModel) renderMerge(pr PullRequestInfo, width int) []string {
+	switch pr.status {
+	case "completed":
+		return []string{"Merge:", checkIcon(checkPassed) + " " + truncate(closedLabel(pr), width-2)}
+	case "abandoned":
+		if !pr.closedDate.IsZero() {
+			return []string{"Merge:", checkIcon(checkFailed) + " " + truncate(closedLabel(pr), width-2)}
+		}
+	}
+	state, label := mergeLabel(pr)
+	lines := []string{"Merge:", checkIcon(state) + " " + truncate(label, width-2)}
+	if pr.autoCompleteSetByID != "" {
+		lines = append(lines, checkIcon(checkPending)+" "+truncate("Auto-complete set by "+pr.autoCompleteSetBy, width-2))
+	}
+	if git.PullRequestAsyncStatus(pr.mergeStatus) != git.PullRequestAsyncStatusValues.Conflicts {
+		return lines
+	}
+	conflicts, ok := m.conflicts[pr.id]
+	switch {
+	case !ok:
+		return append(lines, sepStyle.Render("Loading conflicts..."))
+	case conflicts.err != nil:
+		return append(lines, errorStyle.Render(truncate(conflicts.err.Error(), width)))
+	}
+	for _, path := range conflicts.paths {
+		lines = append(lines, "  "+truncate(path, width-2))
+	}
+	return lines
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"math"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// rect is an area of the screen in cells
+type rect struct {
+	x, y, w, h int
+}
+
+func (r rect) contains(x, y int) bool {
+	return x >= r.x && x < r.x+r.w && y >= r.y && y < r.y+r.h
+}
+
+func (r rect) at(x, y int) rect {
+	r.x += x
+	r.y += y
+	return r
+}
+
+// paneLayout records where a pane and its scrollable content were drawn
+type paneLayout struct {
+	area      rect // the whole pane including its frame
+	content   rect // the rows of the list, relative to the pane
+	first     int  // index of the first visible list row
+	maxScroll int  // how far the detail pane can scroll
+}
+
+// at moves the pane to its position on the screen
+func (p paneLayout) at(x, y int) paneLayout {
+	p.area = p.area.at(x, y)
+	p.content = p.content.at(p.area.x, p.area.y)
+	return p
+}
+
+// toggleHit is a clickable filter toggle in the footer
+type toggleHit struct {
+	area   rect
+	filter filterToggle
+}
+
+func (t toggleHit) at(x, y int) toggleHit {
+	t.area = t.area.at(x, y)
+	return t
+}
+
+// screenLayout is the geometry of the last rendered frame, used for hit-testing
+type screenLayout struct {
+	list       paneLayout
+	detail     paneLayout
+	files      paneLayout
+	diff       paneLayout
+	iterations paneLayout
+	workItems  paneLayout
+	reviewers  paneLayout
+	toggles    []toggleHit
+}
+
+// centerOffset mirrors how lipgloss.Place centers content of size inner in outer
+func centerOffset(outer, inner int) int {
+	gap := outer - inner
+	if gap <= 0 {
+		return 0
+	}
+	return gap - int(math.Round(float64(gap)*0.5))
+}
+
+// shortHelpSpans returns the one-row area each binding occupies in h.ShortHelpView,
+// with a zero width for bindings that were cut off or are disabled
+func shortHelpSpans(h help.Model, bindings []key.Binding) []rect {
+	spans := make([]rect, len(bindings))
+	sepWidth := lipgloss.Width(h.Styles.ShortSeparator.Inline(true).Render(h.ShortSeparator))
+	total := 0
+	for i, kb := range bindings {
+		if !kb.Enabled() {
+			continue
+		}
+		sep := 0
+		if total > 0 {
+			sep = sepWidth
+		}
+		w := lipgloss.Width(kb.Help().Key) + 1 + lipgloss.Width(kb.Help().Desc)
+		if h.Width > 0 && total+sep+w > h.Width {
+			break
+		}
+		spans[i] = rect{x: total + sep, w: w, h: 1}
+		total += sep + w
+	}
+	return spans
+}
+
+// handleMouse selects clicked PRs, scrolls the pane under the wheel and flips
+// clicked footer toggles
+func (m tuiModel) handleMouse(msg tea.MouseMsg) tuiModel {
+	if m.showHelp || m.view == viewForm || msg.Action != tea.MouseActionPress {
+		return m
+	}
+	_, layout := m.render()
+	switch m.view {
+	case viewFiles:
+		return m.handleFilesMouse(msg, layout)
+	case viewIterations:
+		return m.handleIterationsMouse(msg, layout)
+	case viewWorkItems:
+		return m.handleWorkItemsMouse(msg, layout)
+	case viewReviewers:
+		return m.handleReviewersMouse(msg, layout)
+	case viewDiff:
+		if msg.Button == tea.MouseButtonWheelUp {
+			m.diff.scroll = max(0, m.diff.scroll-1)
+		} else if msg.Button == tea.MouseButtonWheelDown {
+			m.diff.scroll = min(m.diff.scroll+1, layout.diff.maxScroll)
+		}
+		return m
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp, tea.MouseButtonWheelDown:
+		delta := 1
+		if msg.Button == tea.MouseButtonWheelUp {
+			delta = -1
+		}
+		switch {
+		case layout.list.area.contains(msg.X, msg.Y):
+			m.moveSelection(delta)
+		case layout.detail.area.contains(msg.X, msg.Y):
+			m.detailScroll = max(0, min(m.detailScroll+delta, layout.detail.maxScroll))
+		}
+	case tea.MouseButtonLeft:
+		if layout.list.content.contains(msg.X, msg.Y) {
+			m.moveSelection(layout.list.first + msg.Y - layout.list.content.y - m.selected)
+			return m
+		}
+		for _, t := range layout.toggles {
+			if t.area.contains(msg.X, msg.Y) {
+				m.toggleFilter(t.filter)
+				return m
+			}
+		}
+	}
+	return m
+}
+
+// handleFilesMouse selects clicked files and moves the selection with the wheel
+func (m tuiModel) handleFilesMouse(msg tea.MouseMsg, layout screenLayout) tuiModel {
+	last := max(0, len(m.files.changes)-1)
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.files.selected = max(0, m.files.selected-1)
+	case tea.MouseButtonWheelDown:
+		m.files.selected = min(m.files.selected+1, last)
+	case tea.MouseButtonLeft:
+		if layout.files.content.contains(msg.X, msg.Y) {
+			m.files.selected = min(layout.files.first+msg.Y-layout.files.content.y, last)
+		}
+	}
+	return m
+}
+
+// handleIterationsMouse selects clicked pushes and moves the selection with the wheel
+func (m tuiModel) handleIterationsMouse(msg tea.MouseMsg, layout screenLayout) tuiModel {
+	last := max(0, len(m.iterations.items)-1)
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.iterations.selected = max(0, m.iterations.selected-1)
+	case tea.MouseButtonWheelDown:
+		m.iterations.selected = min(m.iterations.selected+1, last)
+	case tea.MouseButtonLeft:
+		if layout.iterations.content.contains(msg.X, msg.Y) {
+			m.iterations.selected = min(layout.iterations.first+msg.Y-layout.iterations.content.y, last)
+		}
+	}
+	return m
+}
+
+// handleWorkItemsMouse selects clicked work items and moves the selection with the wheel
+func (m tuiModel) handleWorkItemsMouse(msg tea.MouseMsg, layout screenLayout) tuiModel {
+	last := max(0, len(m.workItems[m.workItemPicker.pr.id].items)-1)
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.workItemPicker.selected = max(0, m.workItemPicker.selected-1)
+	case tea.MouseButtonWheelDown:
+		m.workItemPicker.selected = min(m.workItemPicker.selected+1, last)
+	case tea.MouseButtonLeft:
+		if layout.workItems.content.contains(msg.X, msg.Y) {
+			m.workItemPicker.selected = min(layout.workItems.first+msg.Y-layout.workItems.content.y, last)
+		}
+	}
+	return m
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// notifier delivers watch events to the user
+type notifier interface {
+	notify(event watchEvent) error
+}
+
+// notifiers sends every event to each notifier
+type notifiers []notifier
+
+// dispatch delivers the events, reporting every notifier that failed
+func (ns notifiers) dispatch(events []watchEvent) error {
+	var errs []error
+	for _, event := range events {
+		for _, n := range ns {
+			if err := n.notify(event); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// defaultNotifiers are used when none are configured
+var defaultNotifiers = []string{"desktop", "bell"}
+
+// newNotifiers builds the named notifiers. Without names the defaults are used,
+// skipping desktop notifications when there is no session bus to send them on.
+func newNotifiers(names []string, command string, bell io.Writer) (notifiers, error) {
+	optionalDesktop := len(names) == 0
+	if optionalDesktop {
+		names = defaultNotifiers
+	}
+	var ns notifiers
+	for _, name := range names {
+		switch name {
+		case "desktop":
+			n, err := newDesktopNotifier()
+			if err != nil {
+				if optionalDesktop {
+					continue
+				}
+				return nil, fmt.Errorf("desktop notifications are unavailable: %w", err)
+			}
+			ns = append(ns, n)
+		case "bell":
+			ns = append(ns, bellNotifier{out: bell})
+		case "command":
+			if command == "" {
+				return nil, errors.New("the command notifier needs a command to run")
+			}
+			ns = append(ns, commandNotifier{command: command})
+		default:
+			return nil, fmt.Errorf("unknown notifier %q (available: desktop, bell, command)", name)
+		}
+	}
+	return ns, nil
+}
+
+// notificationBus is the part of a D-Bus object the desktop notifier calls
+type notificationBus interface {
+	Call(method string, flags dbus.Flags, args ...interface{}) *dbus.Call
+}
+
+// desktopNotifier shows freedesktop notifications over the D-Bus session bus
+type desktopNotifier struct {
+	bus notificationBus
+}
+
+func newDesktopNotifier() (desktopNotifier, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return desktopNotifier{}, err
+	}
+	return desktopNotifier{bus: conn.Object("org.freedesktop.Notifications", "/org/freedesktop/Notifications")}, nil
+}
+
+func (n desktopNotifier) notify(event watchEvent) error {
+	call := n.bus.Call("org.freedesktop.Notifications.Notify", 0,
+		"AzurePR", // app name
+		uint32(0), // replaces no earlier notification
+		"",        // icon
+		event.summary(),
+		event.body(),
+		[]string{},                // actions
+		map[string]dbus.Variant{}, // hints
+		int32(-1),                 // expire after the server's default timeout
+	)
+	if call.Err != nil {
+		return fmt.Errorf("failed to send desktop notification: %w", call.Err)
+	}
+	return nil
+}
+
+// bellNotifier rings the terminal bell
+type bellNotifier struct {
+	out io.Writer
+}
+
+func (n bellNotifier) notify(watchEvent) error {
+	_, err := io.WriteString(n.out, "\a")
+	return err
+}
+
+// commandNotifier runs a shell command for every event, describing the event
+// in AZPR_* environment variables
+type commandNotifier struct {
+	command string
+}
+
+func (n commandNotifier) notify(event watchEvent) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", n.command)
+	} else {
+		cmd = exec.Command("sh", "-c", n.command)
+	}
+	cmd.Env = append(os.Environ(),
+		"AZPR_EVENT="+event.kind.String(),
+		"AZPR_PR_ID="+strconv.Itoa(event.pr.id),
+		"AZPR_TITLE="+event.pr.title,
+		"AZPR_URL="+event.pr.url,
+		"AZPR_SUMMARY="+event.summary(),
+		"AZPR_BODY="+event.body(),
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("notify command failed: %w: %s", err, out)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// fakeBus records the calls made to it instead of talking to D-Bus
+type fakeBus struct {
+	methods []string
+	args    [][]interface{}
+	err     error
+}
+
+func (b *fakeBus) Call(method string, flags dbus.Flags, args ...interface{}) *dbus.Call {
+	b.methods = append(b.methods, method)
+	b.args = append(b.args, args)
+	return &dbus.Call{Err: b.err}
+}
+
+func testEvent() watchEvent {
+	return watchEvent{
+		kind:     eventVoteChanged,
+		pr:       PullRequestInfo{id: 42, title: "Fix login", creator: "Ada", url: "https://example.com/pr/42"},
+		reviewer: "Bob",
+		vote:     10,
+	}
+}
+
+func TestDesktopNotifierSendsNotify(t *testing.T) {
+	bus := &fakeBus{}
+	if err := (desktopNotifier{bus: bus}).notify(testEvent()); err != nil {
+		t.Fatal(err)
+	}
+	if len(bus.methods) != 1 || bus.methods[0] != "org.freedesktop.Notifications.Notify" {
+		t.Fatalf("methods = %v, want one Notify call", bus.methods)
+	}
+	args := bus.args[0]
+	if len(args) != 8 {
+		t.Fatalf("got %d arguments, want 8", len(args))
+	}
+	if args[0] != "AzurePR" {
+		t.Errorf("app name = %v", args[0])
+	}
+	if _, ok := args[1].(uint32); !ok {
+		t.Errorf("replaces id is %T, want uint32", args[1])
+	}
+	if args[3] != "Bob voted Approved on !42" {
+		t.Errorf("summary = %v", args[3])
+	}
+	if args[4] != "Fix login (by Ada)" {
+		t.Errorf("body = %v", args[4])
+	}
+	if _, ok := args[6].(map[string]dbus.Variant); !ok {
+		t.Errorf("hints are %T, want map[string]dbus.Variant", args[6])
+	}
+	if timeout, ok := args[7].(int32); !ok || timeout != -1 {
+		t.Errorf("timeout = %v, want int32 -1", args[7])
+	}
+}
+
+func TestDesktopNotifierReportsBusErrors(t *testing.T) {
+	bus := &fakeBus{err: errors.New("no notification daemon")}
+	err := (desktopNotifier{bus: bus}).notify(testEvent())
+	if err == nil || !strings.Contains(err.Error(), "no notification daemon") {
+		t.Fatalf("err = %v, want the bus error", err)
+	}
+}
+
+func TestBellNotifierRings(t *testing.T) {
+	var out bytes.Buffer
+	if err := (bellNotifier{out: &out}).notify(testEvent()); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "\a" {
+		t.Fatalf("wrote %q, want a bell", out.String())
+	}
+}
+
+func TestCommandNotifierPassesEvent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	file := filepath.Join(t.TempDir(), "event")
+	n := commandNotifier{command: `printf '%s|%s|%s' "$AZPR_EVENT" "$AZPR_PR_ID" "$AZPR_SUMMARY" > ` + file}
+	if err := n.notify(testEvent()); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "vote_changed|42|Bob voted Approved on !42"; string(got) != want {
+		t.Fatalf("command saw %q, want %q", got, want)
+	}
+}
+
+func TestCommandNotifierReportsFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	err := (commandNotifier{command: "echo broken; exit 3"}).notify(testEvent())
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Fatalf("err = %v, want the command output", err)
+	}
+}
+
+func TestDispatchSendsEveryEventToEveryNotifier(t *testing.T) {
+	first, second := &fakeBus{}, &fakeBus{err: errors.New("down")}
+	var bell bytes.Buffer
+	ns := notifiers{desktopNotifier{bus: first}, desktopNotifier{bus: second}, bellNotifier{out: &bell}}
+	err := ns.dispatch([]watchEvent{testEvent(), testEvent()})
+	if len(first.methods) != 2 || len(second.methods) != 2 || bell.Len() != 2 {
+		t.Fatalf("calls = %d, %d, %d bells; want 2 each", len(first.methods), len(second.methods), bell.Len())
+	}
+	if err == nil || !strings.Contains(err.Error(), "down") {
+		t.Fatalf("err = %v, want the failing notifier's error", err)
+	}
+}
+
+func TestNewNotifiers(t *testing.T) {
+	tests := []struct {
+		name    string
+		names   []string
+		command string
+		want    int
+		wantErr string
+	}{
+		{name: "bell", names: []string{"bell"}, want: 1},
+		{name: "command", names: []string{"command", "bell"}, command: "true", want: 2},
+		{name: "command without command", names: []string{"command"}, wantErr: "needs a command"},
+		{name: "unknown", names: []string{"pager"}, wantErr: `unknown notifier "pager"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns, err := newNotifiers(tt.names, tt.command, &bytes.Buffer{})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ns) != tt.want {
+				t.Fatalf("got %d notifiers, want %d", len(ns), tt.want)
+			}
+		})
+	}
+}
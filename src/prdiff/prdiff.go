@@ -0,0 +1,143 @@
+// Package prdiff finds what changed between two snapshots of pull requests,
+// such as two successive fetches of the open PRs of a project.
+package prdiff
+
+// Reviewer is a reviewer of a pull request as of a snapshot
+type Reviewer struct {
+	ID       string
+	Name     string
+	Required bool
+	Vote     int // 10 approved, 5 approved with suggestions, 0 no vote, -5 waiting, -10 rejected
+}
+
+// PR is a pull request as of a snapshot
+type PR struct {
+	ID    int
+	Title string
+	Draft bool
+	// SourceCommit is the latest commit of the source branch; it changes on every push
+	SourceCommit string
+	Reviewers    []Reviewer
+}
+
+// Kind is a kind of change
+type Kind int
+
+const (
+	Added Kind = iota
+	Removed
+	TitleChanged
+	Published        // a draft was published
+	ConvertedToDraft // a published PR was turned back into a draft
+	Pushed           // new commits were pushed to the source branch
+	ReviewerAdded
+	ReviewerRemoved
+	VoteChanged
+	RequiredChanged // a reviewer became required or optional
+)
+
+var kindNames = []string{"added", "removed", "title_changed", "published", "converted_to_draft", "pushed", "reviewer_added", "reviewer_removed", "vote_changed", "required_changed"}
+
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return "unknown"
+	}
+	return kindNames[k]
+}
+
+// Change is one difference between two snapshots
+type Change struct {
+	Kind   Kind
+	Before PR // the PR in the old snapshot, zero for Added
+	After  PR // the PR in the new snapshot, zero for Removed
+	// Reviewer is the reviewer a reviewer change is about, as in the new
+	// snapshot or, for ReviewerRemoved, the old one
+	Reviewer Reviewer
+	// PrevReviewer is the reviewer as in the old snapshot, for VoteChanged and RequiredChanged
+	PrevReviewer Reviewer
+}
+
+// PR returns the pull request the change is about, as recent as known
+func (c Change) PR() PR {
+	if c.Kind == Removed {
+		return c.Before
+	}
+	return c.After
+}
+
+// Diff returns the changes from before to after. Changes come in the order of
+// the PRs in after, then PRs removed in the order of before. Within a PR, the
+// PR's own changes come first, then reviewer changes in reviewer order, with
+// removed reviewers last. A newly added reviewer who already voted is only
+// reported as added; their vote is on Reviewer.
+func Diff(before, after []PR) []Change {
+	old := make(map[int]PR, len(before))
+	for _, pr := range before {
+		old[pr.ID] = pr
+	}
+	var changes []Change
+	present := make(map[int]bool, len(after))
+	for _, pr := range after {
+		present[pr.ID] = true
+		prev, ok := old[pr.ID]
+		if !ok {
+			changes = append(changes, Change{Kind: Added, After: pr})
+			continue
+		}
+		changes = append(changes, diffPR(prev, pr)...)
+	}
+	for _, pr := range before {
+		if !present[pr.ID] {
+			changes = append(changes, Change{Kind: Removed, Before: pr})
+		}
+	}
+	return changes
+}
+
+// diffPR compares two snapshots of the same pull request
+func diffPR(before, after PR) []Change {
+	var changes []Change
+	add := func(kind Kind) {
+		changes = append(changes, Change{Kind: kind, Before: before, After: after})
+	}
+	if before.Title != after.Title {
+		add(TitleChanged)
+	}
+	switch {
+	case before.Draft && !after.Draft:
+		add(Published)
+	case !before.Draft && after.Draft:
+		add(ConvertedToDraft)
+	}
+	if before.SourceCommit != after.SourceCommit {
+		add(Pushed)
+	}
+	prev := make(map[string]Reviewer, len(before.Reviewers))
+	for _, rev := range before.Reviewers {
+		prev[rev.ID] = rev
+	}
+	current := make(map[string]bool, len(after.Reviewers))
+	for _, rev := range after.Reviewers {
+		current[rev.ID] = true
+		was, ok := prev[rev.ID]
+		reviewer := func(kind Kind) {
+			changes = append(changes, Change{Kind: kind, Before: before, After: after, Reviewer: rev, PrevReviewer: was})
+		}
+		if !ok {
+			reviewer(ReviewerAdded)
+			continue
+		}
+		if was.Vote != rev.Vote {
+			reviewer(VoteChanged)
+		}
+		if was.Required != rev.Required {
+			reviewer(RequiredChanged)
+		}
+	}
+	for _, rev := range before.Reviewers {
+		if !current[rev.ID] {
+			changes = append(changes, Change{Kind: ReviewerRemoved, Before: before, After: after, Reviewer: rev})
+		}
+	}
+	return changes
+}
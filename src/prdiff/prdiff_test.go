@@ -0,0 +1,175 @@
+package prdiff
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+var (
+	bob   = Reviewer{ID: "bob", Name: "Bob"}
+	eve   = Reviewer{ID: "eve", Name: "Eve", Required: true}
+	base  = PR{ID: 1, Title: "Fix login", SourceCommit: "a1", Reviewers: []Reviewer{bob, eve}}
+	other = PR{ID: 2, Title: "Add search", SourceCommit: "c3"}
+)
+
+// with returns a copy of pr changed by edit, leaving pr's reviewers untouched
+func with(pr PR, edit func(*PR)) PR {
+	pr.Reviewers = append([]Reviewer(nil), pr.Reviewers...)
+	edit(&pr)
+	return pr
+}
+
+func voted(r Reviewer, vote int) Reviewer {
+	r.Vote = vote
+	return r
+}
+
+func required(r Reviewer, required bool) Reviewer {
+	r.Required = required
+	return r
+}
+
+// describe renders changes compactly, e.g. "vote_changed #1 bob 0>10"
+func describe(changes []Change) []string {
+	var out []string
+	for _, c := range changes {
+		s := fmt.Sprintf("%s #%d", c.Kind, c.PR().ID)
+		switch c.Kind {
+		case ReviewerAdded, ReviewerRemoved:
+			s += " " + c.Reviewer.ID
+		case VoteChanged:
+			s += fmt.Sprintf(" %s %d>%d", c.Reviewer.ID, c.PrevReviewer.Vote, c.Reviewer.Vote)
+		case RequiredChanged:
+			s += fmt.Sprintf(" %s %t>%t", c.Reviewer.ID, c.PrevReviewer.Required, c.Reviewer.Required)
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after []PR
+		want          []string
+	}{
+		{name: "both empty"},
+		{name: "nil and empty", before: nil, after: []PR{}},
+		{name: "unchanged", before: []PR{base, other}, after: []PR{base, other}},
+		{name: "reordered PRs", before: []PR{base, other}, after: []PR{other, base}},
+		{name: "reordered reviewers", before: []PR{base}, after: []PR{with(base, func(p *PR) { p.Reviewers = []Reviewer{eve, bob} })}},
+		{name: "first snapshot", after: []PR{base, other}, want: []string{"added #1", "added #2"}},
+		{name: "all removed", before: []PR{base, other}, want: []string{"removed #1", "removed #2"}},
+		{name: "added", before: []PR{base}, after: []PR{base, other}, want: []string{"added #2"}},
+		{name: "removed", before: []PR{base, other}, after: []PR{other}, want: []string{"removed #1"}},
+		{name: "replaced", before: []PR{base}, after: []PR{other}, want: []string{"added #2", "removed #1"}},
+		{name: "title changed", before: []PR{base}, after: []PR{with(base, func(p *PR) { p.Title = "Fix logout" })}, want: []string{"title_changed #1"}},
+		{name: "published", before: []PR{with(base, func(p *PR) { p.Draft = true })}, after: []PR{base}, want: []string{"published #1"}},
+		{name: "converted to draft", before: []PR{base}, after: []PR{with(base, func(p *PR) { p.Draft = true })}, want: []string{"converted_to_draft #1"}},
+		{name: "still a draft", before: []PR{with(base, func(p *PR) { p.Draft = true })}, after: []PR{with(base, func(p *PR) { p.Draft = true })}},
+		{name: "pushed", before: []PR{base}, after: []PR{with(base, func(p *PR) { p.SourceCommit = "b2" })}, want: []string{"pushed #1"}},
+		{name: "first commit known", before: []PR{with(base, func(p *PR) { p.SourceCommit = "" })}, after: []PR{base}, want: []string{"pushed #1"}},
+		{name: "reviewer added", before: []PR{base}, after: []PR{with(base, func(p *PR) { p.Reviewers = append(p.Reviewers, Reviewer{ID: "ada"}) })},
+			want: []string{"reviewer_added #1 ada"}},
+		{name: "reviewer added with a vote only reported as added", before: []PR{base}, after: []PR{with(base, func(p *PR) { p.Reviewers = append(p.Reviewers, Reviewer{ID: "ada", Vote: 10}) })},
+			want: []string{"reviewer_added #1 ada"}},
+		{name: "first reviewer", before: []PR{other}, after: []PR{with(other, func(p *PR) { p.Reviewers = []Reviewer{bob} })}, want: []string{"reviewer_added #2 bob"}},
+		{name: "reviewer removed", before: []PR{base}, after: []PR{with(base, func(p *PR) { p.Reviewers = []Reviewer{eve} })}, want: []string{"reviewer_removed #1 bob"}},
+		{name: "all reviewers removed", before: []PR{base}, after: []PR{with(base, func(p *PR) { p.Reviewers = nil })},
+			want: []string{"reviewer_removed #1 bob", "reviewer_removed #1 eve"}},
+		{name: "reviewer swapped", before: []PR{base}, after: []PR{with(base, func(p *PR) { p.Reviewers = []Reviewer{{ID: "ada"}, eve} })},
+			want: []string{"reviewer_added #1 ada", "reviewer_removed #1 bob"}},
+		{name: "approved", before: []PR{base}, after: []PR{with(base, func(p *PR) { p.Reviewers[0] = voted(bob, 10) })}, want: []string{"vote_changed #1 bob 0>10"}},
+		{name: "approved with suggestions", before: []PR{base}, after: []PR{with(base, func(p *PR) { p.Reviewers[0] = voted(bob, 5) })}, want: []string{"vote_changed #1 bob 0>5"}},
+		{name: "waiting for author", before: []PR{base}, after: []PR{with(base, func(p *PR) { p.Reviewers[1] = voted(eve, -5) })}, want: []string{"vote_changed #1 eve 0>-5"}},
+		{name: "rejected after approving", before: []PR{with(base, func(p *PR) { p.Reviewers[0] = voted(bob, 10) })}, after: []PR{with(base, func(p *PR) { p.Reviewers[0] = voted(bob, -10) })},
+			want: []string{"vote_changed #1 bob 10>-10"}},
+		{name: "vote reset", before: []PR{with(base, func(p *PR) { p.Reviewers[0] = voted(bob, 10) })}, after: []PR{base}, want: []string{"vote_changed #1 bob 10>0"}},
+		{name: "made required", before: []PR{base}, after: []PR{with(base, func(p *PR) { p.Reviewers[0] = required(bob, true) })}, want: []string{"required_changed #1 bob false>true"}},
+		{name: "made optional", before: []PR{base}, after: []PR{with(base, func(p *PR) { p.Reviewers[1] = required(eve, false) })}, want: []string{"required_changed #1 eve true>false"}},
+		{name: "vote and required on one reviewer", before: []PR{base}, after: []PR{with(base, func(p *PR) { p.Reviewers[0] = required(voted(bob, 10), true) })},
+			want: []string{"vote_changed #1 bob 0>10", "required_changed #1 bob false>true"}},
+		{name: "name changes are ignored", before: []PR{base}, after: []PR{with(base, func(p *PR) { p.Reviewers[0].Name = "Robert" })}},
+		{
+			name:   "everything at once",
+			before: []PR{with(base, func(p *PR) { p.Draft = true }), other},
+			after: []PR{with(base, func(p *PR) {
+				p.Title = "Fix login for real"
+				p.SourceCommit = "b2"
+				p.Reviewers = []Reviewer{required(voted(eve, -5), false), {ID: "ada"}}
+			}), {ID: 3, Title: "New"}},
+			want: []string{
+				"title_changed #1", "published #1", "pushed #1",
+				"vote_changed #1 eve 0>-5", "required_changed #1 eve true>false",
+				"reviewer_added #1 ada", "reviewer_removed #1 bob",
+				"added #3", "removed #2",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := describe(Diff(tt.before, tt.after))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Diff() = %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffCarriesSnapshots(t *testing.T) {
+	after := with(base, func(p *PR) { p.Reviewers[0] = voted(bob, 10) })
+	changes := Diff([]PR{base, other}, []PR{after})
+	if len(changes) != 2 {
+		t.Fatalf("got %q, want a vote change and a removal", describe(changes))
+	}
+	vote := changes[0]
+	if !reflect.DeepEqual(vote.Before, base) || !reflect.DeepEqual(vote.After, after) {
+		t.Errorf("vote change has Before %+v and After %+v", vote.Before, vote.After)
+	}
+	if vote.Reviewer != voted(bob, 10) || vote.PrevReviewer != bob {
+		t.Errorf("vote change has Reviewer %+v and PrevReviewer %+v", vote.Reviewer, vote.PrevReviewer)
+	}
+	removed := changes[1]
+	if !reflect.DeepEqual(removed.Before, other) || removed.After.ID != 0 || removed.PR().ID != other.ID {
+		t.Errorf("removal has Before %+v and After %+v", removed.Before, removed.After)
+	}
+	added := Diff(nil, []PR{other})[0]
+	if added.Before.ID != 0 || !reflect.DeepEqual(added.PR(), other) {
+		t.Errorf("addition has Before %+v and PR %+v", added.Before, added.PR())
+	}
+}
+
+func TestDiffLeavesInputsAlone(t *testing.T) {
+	before := []PR{base}
+	after := []PR{with(base, func(p *PR) { p.Reviewers = nil })}
+	Diff(before, after)
+	if len(before[0].Reviewers) != 2 || after[0].Reviewers != nil {
+		t.Fatal("Diff modified its inputs")
+	}
+}
+
+func TestKindString(t *testing.T) {
+	tests := []struct {
+		kind Kind
+		want string
+	}{
+		{Added, "added"},
+		{Removed, "removed"},
+		{TitleChanged, "title_changed"},
+		{Published, "published"},
+		{ConvertedToDraft, "converted_to_draft"},
+		{Pushed, "pushed"},
+		{ReviewerAdded, "reviewer_added"},
+		{ReviewerRemoved, "reviewer_removed"},
+		{VoteChanged, "vote_changed"},
+		{RequiredChanged, "required_changed"},
+		{Kind(-1), "unknown"},
+		{Kind(len(kindNames)), "unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.kind.String(); got != tt.want {
+			t.Errorf("Kind(%d).String() = %q, want %q", tt.kind, got, tt.want)
+		}
+	}
+}
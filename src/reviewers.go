@@ -0,0 +1,466 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+)
+
+// reviewersState is the reviewer list of a PR and the identity picker used to
+// add reviewers to it
+type reviewersState struct {
+	prID     int
+	selected int
+	busy     bool
+	// The picker searches as the query is typed
+	picking   bool
+	query     formField
+	found     []identityInfo // server matches for the latest search
+	searching bool
+	seq       int // bumped on every edit so stale searches are dropped
+	pick      int
+	err       error
+}
+
+// Picker keys are fixed, since letters go to the query
+var (
+	pickerMove   = key.NewBinding(key.WithKeys("up", "down"), key.WithHelp("↑/↓", "choose"))
+	pickerChoose = key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "add reviewer"))
+)
+
+// identitySearchDelay is how long typing has to pause before the server is searched
+const identitySearchDelay = 300 * time.Millisecond
+
+// identitySearchMsg fires when typing in the picker paused
+type identitySearchMsg struct{ seq int }
+
+// identitiesFoundMsg carries the server matches for a picker query
+type identitiesFoundMsg struct {
+	seq   int
+	items []identityInfo
+	err   error
+}
+
+// reviewersUpdatedMsg carries the reviewers of a PR after a change
+type reviewersUpdatedMsg struct {
+	prID      int
+	reviewers []PullrequestReviewer
+	text      string
+	err       error
+}
+
+// AddReviewer adds an optional reviewer to the pull request
+func (s *Session) AddReviewer(pr PullRequestInfo, ident identityInfo) (PullrequestReviewer, error) {
+	vote, required := 0, false
+	rev, err := s.gitClient.CreatePullRequestReviewer(s.ctx, git.CreatePullRequestReviewerArgs{
+		Reviewer:      &git.IdentityRefWithVote{Id: &ident.id, Vote: &vote, IsRequired: &required},
+		RepositoryId:  &pr.repositoryID,
+		PullRequestId: &pr.id,
+		ReviewerId:    &ident.id,
+		Project:       &s.project,
+	})
+	if err != nil {
+		return PullrequestReviewer{}, err
+	}
+	return createReviewer(*rev), nil
+}
+
+// SetReviewerRequired makes a reviewer of the pull request required or optional
+func (s *Session) SetReviewerRequired(pr PullRequestInfo, reviewerID string, required bool) (PullrequestReviewer, error) {
+	rev, err := s.gitClient.CreatePullRequestReviewer(s.ctx, git.CreatePullRequestReviewerArgs{
+		Reviewer:      &git.IdentityRefWithVote{Id: &reviewerID, IsRequired: &required},
+		RepositoryId:  &pr.repositoryID,
+		PullRequestId: &pr.id,
+		ReviewerId:    &reviewerID,
+		Project:       &s.project,
+	})
+	if err != nil {
+		return PullrequestReviewer{}, err
+	}
+	return createReviewer(*rev), nil
+}
+
+// RemoveReviewer removes a reviewer from the pull request
+func (s *Session) RemoveReviewer(pr PullRequestInfo, reviewerID string) error {
+	return s.gitClient.DeletePullRequestReviewer(s.ctx, git.DeletePullRequestReviewerArgs{
+		RepositoryId:  &pr.repositoryID,
+		PullRequestId: &pr.id,
+		ReviewerId:    &reviewerID,
+		Project:       &s.project,
+	})
+}
+
+// prByID returns the loaded PR with the given id
+func (m tuiModel) prByID(id int) (PullRequestInfo, bool) {
+	for _, pr := range m.prs {
+		if pr.id == id {
+			return pr, true
+		}
+	}
+	return PullRequestInfo{}, false
+}
+
+// openReviewers shows the reviewers of the selected PR
+func (m tuiModel) openReviewers() (tea.Model, tea.Cmd) {
+	pr, ok := m.selectedPR()
+	if !ok {
+		return m, nil
+	}
+	m.view = viewReviewers
+	m.reviewers = reviewersState{prID: pr.id}
+	return m, nil
+}
+
+// updateReviewers handles keys in the reviewer list
+func (m tuiModel) updateReviewers(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.reviewers.picking {
+		return m.updatePicker(msg)
+	}
+	pr, _ := m.prByID(m.reviewers.prID)
+	reviewers := listedReviewers(pr)
+	r := &m.reviewers
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		r.selected = max(0, r.selected-1)
+	case key.Matches(msg, m.keys.Down):
+		r.selected = max(0, min(r.selected+1, len(reviewers)-1))
+	case key.Matches(msg, m.keys.Help):
+		m.showHelp = true
+	case key.Matches(msg, m.keys.Quit):
+		m.view = viewPRs
+	case r.busy:
+	case key.Matches(msg, m.keys.AddReviewer):
+		r.picking = true
+		r.query = textField("", "")
+		r.found, r.pick, r.err = nil, 0, nil
+	case key.Matches(msg, m.keys.RemoveReviewer, m.keys.ToggleRequired):
+		if r.selected >= len(reviewers) {
+			return m, nil
+		}
+		rev := reviewers[r.selected]
+		if key.Matches(msg, m.keys.RemoveReviewer) && rev.isRequired {
+			return m, func() tea.Msg {
+				return statusMsg{err: fmt.Errorf("%s is required; make them optional before removing them", rev.displayName)}
+			}
+		}
+		r.busy = true
+		if key.Matches(msg, m.keys.RemoveReviewer) {
+			return m, m.removeReviewerCmd(pr, rev)
+		}
+		return m, m.toggleRequiredCmd(pr, rev)
+	}
+	return m, nil
+}
+
+// updatePicker handles keys in the identity picker
+func (m tuiModel) updatePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	r := &m.reviewers
+	candidates := m.reviewerCandidates()
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		r.picking = false
+		return m, nil
+	case tea.KeyUp:
+		r.pick = max(0, r.pick-1)
+		return m, nil
+	case tea.KeyDown:
+		r.pick = max(0, min(r.pick+1, len(candidates)-1))
+		return m, nil
+	case tea.KeyEnter:
+		if r.busy || r.pick >= len(candidates) {
+			return m, nil
+		}
+		pr, _ := m.prByID(r.prID)
+		r.busy = true
+		r.picking = false
+		return m, m.addReviewerCmd(pr, candidates[r.pick])
+	}
+	before := r.query.value()
+	r.query.edit(msg)
+	if r.query.value() == before {
+		return m, nil
+	}
+	r.seq++
+	r.pick = 0
+	seq := r.seq
+	return m, tea.Tick(identitySearchDelay, func(time.Time) tea.Msg { return identitySearchMsg{seq: seq} })
+}
+
+// searchIdentitiesCmd searches the organization for the picker query
+func (m tuiModel) searchIdentitiesCmd() tea.Cmd {
+	session, seq, query := m.session, m.reviewers.seq, m.reviewers.query.value()
+	return func() tea.Msg {
+		if session == nil {
+			return identitiesFoundMsg{seq: seq, err: errors.New("not connected")}
+		}
+		items, err := session.SearchIdentities(query)
+		return identitiesFoundMsg{seq: seq, items: items, err: err}
+	}
+}
+
+// reviewerCandidates returns the people matching the picker query, best match
+// first: everyone seen on the loaded PRs plus what the server found, leaving
+// out the PR's current reviewers
+func (m tuiModel) reviewerCandidates() []identityInfo {
+	query := m.reviewers.query.value()
+	if query == "" {
+		return nil
+	}
+	pr, _ := m.prByID(m.reviewers.prID)
+	seen := make(map[string]bool)
+	for _, rev := range pr.reviewers {
+		seen[rev.id] = true
+	}
+	var known []identityInfo
+	add := func(ident identityInfo) {
+		if ident.id != "" && !seen[ident.id] {
+			seen[ident.id] = true
+			known = append(known, ident)
+		}
+	}
+	// Server matches come first so they keep their email
+	for _, ident := range m.reviewers.found {
+		add(ident)
+	}
+	for _, other := range m.prs {
+		add(identityInfo{id: other.creatorID, displayName: other.creator})
+		for _, rev := range other.reviewers {
+			add(identityInfo{id: rev.id, displayName: rev.displayName})
+		}
+	}
+	type scored struct {
+		ident identityInfo
+		score int
+	}
+	var matches []scored
+	for _, ident := range known {
+		if score, ok := fuzzyScore(query, ident.label()); ok {
+			matches = append(matches, scored{ident, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return strings.ToLower(matches[i].ident.displayName) < strings.ToLower(matches[j].ident.displayName)
+	})
+	candidates := make([]identityInfo, len(matches))
+	for i, match := range matches {
+		candidates[i] = match.ident
+	}
+	return candidates
+}
+
+// addReviewerCmd adds the picked identity as an optional reviewer
+func (m tuiModel) addReviewerCmd(pr PullRequestInfo, ident identityInfo) tea.Cmd {
+	session := m.session
+	return func() tea.Msg {
+		if session == nil {
+			return reviewersUpdatedMsg{prID: pr.id, err: errors.New("not connected")}
+		}
+		rev, err := session.AddReviewer(pr, ident)
+		if err != nil {
+			return reviewersUpdatedMsg{prID: pr.id, err: err}
+		}
+		if rev.displayName == "" {
+			rev.displayName = ident.displayName
+		}
+		reviewers := append(append([]PullrequestReviewer(nil), pr.reviewers...), rev)
+		return reviewersUpdatedMsg{prID: pr.id, reviewers: reviewers, text: fmt.Sprintf("Added %s to !%d", rev.displayName, pr.id)}
+	}
+}
+
+// removeReviewerCmd removes an optional reviewer
+func (m tuiModel) removeReviewerCmd(pr PullRequestInfo, rev PullrequestReviewer) tea.Cmd {
+	session := m.session
+	return func() tea.Msg {
+		if session == nil {
+			return reviewersUpdatedMsg{prID: pr.id, err: errors.New("not connected")}
+		}
+		if err := session.RemoveReviewer(pr, rev.id); err != nil {
+			return reviewersUpdatedMsg{prID: pr.id, err: err}
+		}
+		var reviewers []PullrequestReviewer
+		for _, other := range pr.reviewers {
+			if other.id != rev.id {
+				reviewers = append(reviewers, other)
+			}
+		}
+		return reviewersUpdatedMsg{prID: pr.id, reviewers: reviewers, text: fmt.Sprintf("Removed %s from !%d", rev.displayName, pr.id)}
+	}
+}
+
+// toggleRequiredCmd flips a reviewer between required and optional
+func (m tuiModel) toggleRequiredCmd(pr PullRequestInfo, rev PullrequestReviewer) tea.Cmd {
+	session := m.session
+	return func() tea.Msg {
+		if session == nil {
+			return reviewersUpdatedMsg{prID: pr.id, err: errors.New("not connected")}
+		}
+		updated, err := session.SetReviewerRequired(pr, rev.id, !rev.isRequired)
+		if err != nil {
+			return reviewersUpdatedMsg{prID: pr.id, err: err}
+		}
+		reviewers := append([]PullrequestReviewer(nil), pr.reviewers...)
+		for i, other := range reviewers {
+			if other.id == rev.id {
+				reviewers[i].isRequired = updated.isRequired
+			}
+		}
+		state := "optional"
+		if updated.isRequired {
+			state = "required"
+		}
+		return reviewersUpdatedMsg{prID: pr.id, reviewers: reviewers, text: fmt.Sprintf("%s is now %s on !%d", rev.displayName, state, pr.id)}
+	}
+}
+
+// setReviewers stores the reviewers of a PR after a change
+func (m *tuiModel) setReviewers(msg reviewersUpdatedMsg) {
+	if msg.prID == m.reviewers.prID {
+		m.reviewers.busy = false
+	}
+	if msg.err != nil {
+		m.status = statusMsg{err: msg.err}
+		return
+	}
+	for i := range m.prs {
+		if m.prs[i].id == msg.prID {
+			m.prs[i].reviewers = msg.reviewers
+		}
+	}
+	// Removing yourself takes the PR out of the default reviewer-only list
+	m.selected = max(0, min(m.selected, len(m.filteredPRs())-1))
+	m.reviewers.selected = max(0, min(m.reviewers.selected, len(listedReviewers(PullRequestInfo{reviewers: msg.reviewers}))-1))
+	m.status = statusMsg{text: msg.text}
+}
+
+// listedReviewers returns the reviewers shown for a PR, leaving out ignoredReviewerIDs
+func listedReviewers(pr PullRequestInfo) []PullrequestReviewer {
+	var reviewers []PullrequestReviewer
+	for _, rev := range pr.reviewers {
+		if !ignoredReviewerIDs[rev.id] {
+			reviewers = append(reviewers, rev)
+		}
+	}
+	return reviewers
+}
+
+// reviewerRow formats one reviewer as a list row
+func reviewerRow(rev PullrequestReviewer, width int) string {
+	required := "Optional"
+	if rev.isRequired {
+		required = "Required"
+	}
+	tail := fmt.Sprintf(" %-8s  %-8s", required, voteLabel(rev.vote))
+	nameWidth := max(0, width-lipgloss.Width(tail))
+	return fmt.Sprintf("%-*s", nameWidth, truncate(rev.displayName, nameWidth)) + tail
+}
+
+// renderReviewers renders the reviewers of a PR and, while adding one, the
+// identity picker
+func (m tuiModel) renderReviewers(width, height int) (string, paneLayout) {
+	frameWidth, frameHeight := boxStyle.GetFrameSize()
+	usableWidth := width - frameWidth
+	r := m.reviewers
+	pr, _ := m.prByID(r.prID)
+	reviewers := listedReviewers(pr)
+	header := titleStyle.UnsetHeight().UnsetMarginBottom().Width(usableWidth).Render(fmt.Sprintf("Reviewers of !%d %s", pr.id, pr.title))
+	lines := []string{header, ""}
+	// Keep room for the busy line, and for the picker while it is open
+	visible := max(1, height-frameHeight-len(lines)-2)
+	if r.picking {
+		visible = max(1, visible/2)
+	}
+	first := 0
+	if r.selected >= visible {
+		first = r.selected - visible + 1
+	}
+	last := min(len(reviewers), first+visible)
+	pane := paneLayout{
+		first: first,
+		content: rect{
+			x: boxStyle.GetMarginLeft() + boxStyle.GetBorderLeftSize() + boxStyle.GetPaddingLeft(),
+			y: boxStyle.GetBorderTopSize() + boxStyle.GetPaddingTop() + len(lines),
+			w: usableWidth,
+			h: last - first,
+		},
+	}
+	if len(reviewers) == 0 {
+		lines = append(lines, sepStyle.Render("No reviewers."))
+	}
+	for i := first; i < last; i++ {
+		rev := reviewers[i]
+		cursor := " "
+		if i == r.selected && !r.picking {
+			cursor = ">"
+		}
+		line := cursor + " " + reviewerRow(rev, max(0, usableWidth-2))
+		if i == r.selected && !r.picking {
+			line = selectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	if r.busy {
+		lines = append(lines, "", sepStyle.Render("Working..."))
+	}
+	if r.picking {
+		prompt := "Add reviewer: "
+		lines = append(lines, "", prompt+r.query.render(max(10, usableWidth-len(prompt)), true))
+		candidates := m.reviewerCandidates()
+		visible := max(1, height-frameHeight-len(lines)-1)
+		first := 0
+		if r.pick >= visible {
+			first = r.pick - visible + 1
+		}
+		for i := first; i < min(len(candidates), first+visible); i++ {
+			cursor := " "
+			if i == r.pick {
+				cursor = ">"
+			}
+			line := truncate(cursor+" "+candidates[i].label(), usableWidth)
+			if i == r.pick {
+				line = selectedStyle.Render(line)
+			}
+			lines = append(lines, line)
+		}
+		switch {
+		case r.err != nil:
+			lines = append(lines, errorStyle.Render(truncate(r.err.Error(), usableWidth)))
+		case r.searching:
+			lines = append(lines, sepStyle.Render("Searching..."))
+		case len(candidates) == 0 && r.query.value() != "":
+			lines = append(lines, sepStyle.Render("No matches."))
+		}
+	}
+	box := boxStyle.Width(width - boxStyle.GetHorizontalMargins() - boxStyle.GetHorizontalBorderSize()).Render(strings.Join(lines, "\n"))
+	pane.area = rect{w: lipgloss.Width(box), h: lipgloss.Height(box)}
+	return box, pane
+}
+
+// handleReviewersMouse selects clicked reviewers and moves the selection with the wheel
+func (m tuiModel) handleReviewersMouse(msg tea.MouseMsg, layout screenLayout) tuiModel {
+	if m.reviewers.picking {
+		return m
+	}
+	pr, _ := m.prByID(m.reviewers.prID)
+	last := max(0, len(listedReviewers(pr))-1)
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.reviewers.selected = max(0, m.reviewers.selected-1)
+	case tea.MouseButtonWheelDown:
+		m.reviewers.selected = min(m.reviewers.selected+1, last)
+	case tea.MouseButtonLeft:
+		if layout.reviewers.content.contains(msg.X, msg.Y) {
+			m.reviewers.selected = min(layout.reviewers.first+msg.Y-layout.reviewers.content.y, last)
+		}
+	}
+	return m
+}
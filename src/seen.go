@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// seenRecord is what a PR looked like when the user last marked it read
+type seenRecord struct {
+	Iteration int            `json:"iteration"`
+	Threads   int            `json:"threads"`
+	Comments  int            `json:"comments"`
+	Votes     map[string]int `json:"votes"` // by reviewer id
+}
+
+// seenStore keeps the seen records of one organization and project on disk
+type seenStore struct {
+	path    string
+	records map[int]seenRecord
+	fresh   bool         // there was no file to load, so the first list is taken as seen
+	pending map[int]bool // marked read before their activity loaded
+}
+
+func newSeenStore() seenStore {
+	return seenStore{records: make(map[int]seenRecord), pending: make(map[int]bool)}
+}
+
+// SeenPath returns where the seen records of a profile are kept, next to the config file
+func SeenPath(organization, project string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	name := url.PathEscape(organization) + "_" + url.PathEscape(project) + ".json"
+	return filepath.Join(dir, configDirName, "seen", name), nil
+}
+
+// LoadSeen reads the seen records of a profile, returning an empty store if there are none
+func LoadSeen(organization, project string) (seenStore, error) {
+	store := newSeenStore()
+	path, err := SeenPath(organization, project)
+	if err != nil {
+		return store, err
+	}
+	store.path = path
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		store.fresh = true
+		return store, nil
+	}
+	if err != nil {
+		return store, err
+	}
+	if err := json.Unmarshal(data, &store.records); err != nil {
+		return store, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return store, nil
+}
+
+// save writes the records back, creating the directory on first use
+func (s seenStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.records, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0o644)
+}
+
+// seenSnapshot records the PR as it is now. Only active PRs load activity, so
+// closed ones keep the counts of the previous record.
+func (m tuiModel) seenSnapshot(pr PullRequestInfo) seenRecord {
+	record := m.seen.records[pr.id]
+	if activity, ok := m.activity[pr.id]; ok {
+		record.Iteration = activity.iteration
+		record.Threads = activity.threads
+		record.Comments = activity.comments
+	}
+	record.Votes = make(map[string]int, len(pr.reviewers))
+	for _, rev := range pr.reviewers {
+		record.Votes[rev.id] = rev.vote
+	}
+	return record
+}
+
+// markRead records the given PRs as seen and saves the store. An active PR
+// whose activity has not loaded yet is recorded once it has, as recording zero
+// counts would turn every push into a new one.
+func (m tuiModel) markRead(prs ...PullRequestInfo) tuiModel {
+	recorded := false
+	for _, pr := range prs {
+		if _, loaded := m.activity[pr.id]; !loaded && pr.status == "active" {
+			m.seen.pending[pr.id] = true
+			continue
+		}
+		delete(m.seen.pending, pr.id)
+		m.seen.records[pr.id] = m.seenSnapshot(pr)
+		recorded = true
+	}
+	if !recorded {
+		return m
+	}
+	if err := m.seen.save(); err != nil {
+		m.status = statusMsg{err: fmt.Errorf("failed to save read state: %w", err)}
+	}
+	return m
+}
+
+// seedSeen takes the first listed PRs as seen when the store is new, so the
+// first run does not flag every PR as new. PRs listed later are new again.
+func (m tuiModel) seedSeen() tuiModel {
+	if !m.seen.fresh {
+		return m
+	}
+	m.seen.fresh = false
+	var unrecorded []PullRequestInfo
+	for _, pr := range m.prs {
+		if _, ok := m.seen.records[pr.id]; !ok && !m.seen.pending[pr.id] {
+			unrecorded = append(unrecorded, pr)
+		}
+	}
+	return m.markRead(unrecorded...)
+}
+
+// activityLoaded records a PR that was marked read while its activity loaded.
+// When the load failed it is left unread, as there is nothing to record.
+func (m tuiModel) activityLoaded(prID int, err error) tuiModel {
+	if !m.seen.pending[prID] {
+		return m
+	}
+	delete(m.seen.pending, prID)
+	if err != nil {
+		m.status = statusMsg{err: fmt.Errorf("failed to mark !%d read: %w", prID, err)}
+		return m
+	}
+	for _, pr := range m.prs {
+		if pr.id == prID {
+			return m.markRead(pr)
+		}
+	}
+	return m
+}
+
+// unseenChanges describes what changed on the PR since the user last marked it
+// read, empty when nothing did
+func (m tuiModel) unseenChanges(pr PullRequestInfo) []string {
+	record, ok := m.seen.records[pr.id]
+	switch {
+	case !ok && m.seen.pending[pr.id]:
+		return nil
+	case !ok:
+		return []string{"New pull request"}
+	}
+	var changes []string
+	if activity, ok := m.activity[pr.id]; ok {
+		if n := activity.iteration - record.Iteration; n > 0 {
+			changes = append(changes, plural(n, "new push", "new pushes"))
+		}
+		if n := activity.threads - record.Threads; n > 0 {
+			changes = append(changes, plural(n, "new comment thread", "new comment threads"))
+		} else if n := activity.comments - record.Comments; n > 0 {
+			changes = append(changes, plural(n, "new reply", "new replies"))
+		}
+	}
+	current := make(map[string]bool, len(pr.reviewers))
+	for _, rev := range pr.reviewers {
+		current[rev.id] = true
+		vote, known := record.Votes[rev.id]
+		switch {
+		case !known:
+			changes = append(changes, rev.displayName+" added as reviewer")
+		case vote != rev.vote:
+			changes = append(changes, fmt.Sprintf("%s: %s → %s", rev.displayName, voteLabel(vote), voteLabel(rev.vote)))
+		}
+	}
+	removed := 0
+	for id := range record.Votes {
+		if !current[id] {
+			removed++
+		}
+	}
+	if removed > 0 {
+		changes = append(changes, plural(removed, "reviewer removed", "reviewers removed"))
+	}
+	return changes
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
+
+// renderSeen lists what changed since the PR was last marked read, for the detail pane
+func (m tuiModel) renderSeen(pr PullRequestInfo, width int) []string {
+	changes := m.unseenChanges(pr)
+	if len(changes) == 0 {
+		return nil
+	}
+	lines := []string{"Changed since you last looked:"}
+	for _, change := range changes {
+		lines = append(lines, lipgloss.NewStyle().Bold(true).Render("• "+truncate(change, width-2)))
+	}
+	return append(lines, "")
+}
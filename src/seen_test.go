@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestMarkReadBeforeActivityLoads(t *testing.T) {
+	m := testModel(reviewedByMe(1, "Open"))
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(m.keys.MarkRead.Keys()[0])})
+	if changes := m.unseenChanges(m.prs[0]); len(changes) != 0 {
+		t.Fatalf("unseenChanges() = %q before the activity loaded, want none", changes)
+	}
+	m = update(t, m, activityLoadedMsg{prID: 1, activity: prActivity{iteration: 3, threads: 2, comments: 5}})
+	if changes := m.unseenChanges(m.prs[0]); len(changes) != 0 {
+		t.Fatalf("unseenChanges() = %q after the activity loaded, want none", changes)
+	}
+	m = update(t, m, activityLoadedMsg{prID: 1, activity: prActivity{iteration: 4, threads: 2, comments: 5}})
+	if changes, want := m.unseenChanges(m.prs[0]), []string{"1 new push"}; !reflect.DeepEqual(changes, want) {
+		t.Fatalf("unseenChanges() = %q after a push, want %q", changes, want)
+	}
+}
+
+func TestFirstRunSeedsSeen(t *testing.T) {
+	closed := reviewedByMe(2, "Merged")
+	closed.status = "completed"
+	m := testModel(reviewedByMe(1, "Open"), closed)
+	m.seen.fresh = true
+	m = m.seedSeen()
+	for _, pr := range m.prs {
+		if changes := m.unseenChanges(pr); len(changes) != 0 {
+			t.Fatalf("unseenChanges(!%d) = %q on the first run, want none", pr.id, changes)
+		}
+	}
+	m = update(t, m, activityLoadedMsg{prID: 1, activity: prActivity{iteration: 2}})
+	if changes := m.unseenChanges(m.prs[0]); len(changes) != 0 {
+		t.Fatalf("unseenChanges() = %q once the activity loaded, want none", changes)
+	}
+
+	// A refresh listing a PR created since is not seeded
+	created := reviewedByMe(3, "Created later")
+	m = update(t, m, prsLoadedMsg{mode: m.statusMode, prs: append(m.prs, created)})
+	if changes, want := m.unseenChanges(created), []string{"New pull request"}; !reflect.DeepEqual(changes, want) {
+		t.Fatalf("unseenChanges() = %q for a PR listed later, want %q", changes, want)
+	}
+	if _, ok := m.seen.records[created.id]; ok || m.seen.pending[created.id] {
+		t.Fatal("a PR listed later was marked read")
+	}
+}
+
+func TestMarkReadWhenActivityFails(t *testing.T) {
+	m := testModel(reviewedByMe(1, "Open"))
+	m = m.markRead(m.prs[0])
+	m = update(t, m, activityLoadedMsg{prID: 1, err: errors.New("boom")})
+	if m.seen.pending[1] {
+		t.Fatal("!1 is still waiting for its activity")
+	}
+	if m.status.err == nil || !strings.Contains(m.status.err.Error(), "boom") {
+		t.Fatalf("status = %v, want the load error", m.status.err)
+	}
+	if changes, want := m.unseenChanges(m.prs[0]), []string{"New pull request"}; !reflect.DeepEqual(changes, want) {
+		t.Fatalf("unseenChanges() = %q, want %q", changes, want)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/identity"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/policy"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/workitemtracking"
+)
+
+// Session holds the connection details the TUI needs to call Azure DevOps
+type Session struct {
+	ctx          context.Context
+	connection   *azuredevops.Connection
+	gitClient    git.Client
+	organization string
+	project      string
+
+	// Clients other than git are created on first use, from any goroutine
+	mu             sync.Mutex
+	policyClient   policy.Client
+	workItemClient workitemtracking.Client
+	identityClient identity.Client
+}
+
+// NewSession bundles an authenticated connection for use by the TUI
+func NewSession(ctx context.Context, connection *azuredevops.Connection, gitClient git.Client, organization, project string) *Session {
+	return &Session{
+		ctx:          ctx,
+		connection:   connection,
+		gitClient:    gitClient,
+		organization: organization,
+		project:      project,
+	}
+}
+
+// policy returns the policy client, connecting on first use
+func (s *Session) policy() (policy.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.policyClient == nil {
+		client, err := policy.NewClient(s.ctx, s.connection)
+		if err != nil {
+			return nil, err
+		}
+		s.policyClient = client
+	}
+	return s.policyClient, nil
+}
+
+// workItemTracking returns the work item tracking client, connecting on first use
+func (s *Session) workItemTracking() (workitemtracking.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.workItemClient == nil {
+		client, err := workitemtracking.NewClient(s.ctx, s.connection)
+		if err != nil {
+			return nil, err
+		}
+		s.workItemClient = client
+	}
+	return s.workItemClient, nil
+}
+
+// identities returns the identity client, connecting on first use
+func (s *Session) identities() (identity.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.identityClient == nil {
+		client, err := identity.NewClient(s.ctx, s.connection)
+		if err != nil {
+			return nil, err
+		}
+		s.identityClient = client
+	}
+	return s.identityClient, nil
+}
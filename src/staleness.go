@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// agePolicy decides how old a waiting PR is and when that is too old
+type agePolicy struct {
+	warn         time.Duration
+	stale        time.Duration
+	skipWeekends bool
+	holidays     map[string]bool // dates as 2006-01-02
+}
+
+// Default thresholds follow a "review within one working day" rule
+const (
+	defaultWarnHours  = 12
+	defaultStaleHours = 24
+)
+
+// newAgePolicy builds the age policy from the config
+func newAgePolicy(cfg StalenessConfig) (agePolicy, error) {
+	policy := agePolicy{
+		warn:         hours(cfg.WarnHours, defaultWarnHours),
+		stale:        hours(cfg.StaleHours, defaultStaleHours),
+		skipWeekends: cfg.SkipWeekends,
+		holidays:     make(map[string]bool),
+	}
+	for _, day := range cfg.Holidays {
+		date, err := time.ParseInLocation("2006-01-02", day, time.Local)
+		if err != nil {
+			return policy, fmt.Errorf("holiday %q is not a YYYY-MM-DD date", day)
+		}
+		policy.holidays[date.Format("2006-01-02")] = true
+	}
+	if policy.warn > policy.stale {
+		return policy, fmt.Errorf("warn_hours (%v) is after stale_hours (%v)", policy.warn.Hours(), policy.stale.Hours())
+	}
+	return policy, nil
+}
+
+func hours(h, fallback float64) time.Duration {
+	if h <= 0 {
+		h = fallback
+	}
+	return time.Duration(h * float64(time.Hour))
+}
+
+// counts reports whether time on the day of t counts towards a PR's age
+func (p agePolicy) counts(t time.Time) bool {
+	if p.skipWeekends && (t.Weekday() == time.Saturday || t.Weekday() == time.Sunday) {
+		return false
+	}
+	return !p.holidays[t.Format("2006-01-02")]
+}
+
+// age returns the time between from and now, leaving out weekends and holidays
+// when the policy skips them
+func (p agePolicy) age(from, now time.Time) time.Duration {
+	if from.IsZero() || !now.After(from) {
+		return 0
+	}
+	if !p.skipWeekends && len(p.holidays) == 0 {
+		return now.Sub(from)
+	}
+	var total time.Duration
+	from, now = from.Local(), now.Local()
+	for t := from; t.Before(now); {
+		y, mo, d := t.Date()
+		next := time.Date(y, mo, d+1, 0, 0, 0, 0, time.Local)
+		if next.After(now) {
+			next = now
+		}
+		if p.counts(t) {
+			total += next.Sub(t)
+		}
+		t = next
+	}
+	return total
+}
+
+// style colors an age by how it compares to the thresholds
+func (p agePolicy) style(age time.Duration) lipgloss.Style {
+	switch {
+	case age >= p.stale:
+		return checkFailStyle
+	case age >= p.warn:
+		return checkPendingStyle
+	}
+	return sepStyle
+}
+
+// formatAge shows a duration the way the list does: 45m, 5h, 3d
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
+
+// activityLoadedMsg carries what happened on a PR lately
+type activityLoadedMsg struct {
+	prID     int
+	activity prActivity
+	err      error
+}
+
+// loadActivityCmd fetches the recent activity of every PR
+func loadActivityCmd(session *Session, userID string, prs []PullRequestInfo) tea.Cmd {
+	if session == nil {
+		return nil
+	}
+	cmds := make([]tea.Cmd, 0, len(prs))
+	for _, pr := range prs {
+		cmds = append(cmds, func() tea.Msg {
+			checksLimit <- struct{}{}
+			defer func() { <-checksLimit }()
+			activity, err := session.PullRequestActivity(pr, userID)
+			return activityLoadedMsg{prID: pr.id, activity: activity, err: err}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// lastActivity returns when a PR last saw activity, falling back to its creation
+// until the activity has loaded
+func (m tuiModel) lastActivity(pr PullRequestInfo) time.Time {
+	if activity, ok := m.activity[pr.id]; ok && activity.last.After(pr.createdDate) {
+		return activity.last
+	}
+	return pr.createdDate
+}
+
+// ageIndicator renders how long an open PR has been waiting, colored by the
+// age policy. Drafts are not waiting on anyone and stay uncolored.
+func (m tuiModel) ageIndicator(pr PullRequestInfo) string {
+	if pr.closed() || pr.createdDate.IsZero() {
+		return ""
+	}
+	age := m.ages.age(m.lastActivity(pr), time.Now())
+	if pr.IsDraft {
+		return sepStyle.Render(formatAge(age))
+	}
+	return m.ages.style(age).Render(formatAge(age))
+}
+
+// renderAge describes the age of a PR for the detail pane
+func (m tuiModel) renderAge(pr PullRequestInfo, width int) []string {
+	if pr.createdDate.IsZero() {
+		return nil
+	}
+	now := time.Now()
+	line := fmt.Sprintf("Opened %s ago", formatAge(m.ages.age(pr.createdDate, now)))
+	if !pr.closed() {
+		line += fmt.Sprintf(", last activity %s ago", m.ageIndicator(pr))
+		if _, ok := m.activity[pr.id]; !ok {
+			line += " (loading)"
+		}
+	}
+	return []string{lipgloss.NewStyle().Width(width).Render(line)}
+}
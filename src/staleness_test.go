@@ -0,0 +1,107 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestNewAgePolicy(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   StalenessConfig
+		warn  time.Duration
+		stale time.Duration
+		err   string
+	}{
+		{name: "defaults", warn: 12 * time.Hour, stale: 24 * time.Hour},
+		{name: "configured", cfg: StalenessConfig{WarnHours: 1.5, StaleHours: 8}, warn: 90 * time.Minute, stale: 8 * time.Hour},
+		{name: "negative falls back", cfg: StalenessConfig{WarnHours: -1, StaleHours: 48}, warn: 12 * time.Hour, stale: 48 * time.Hour},
+		{name: "warn after stale", cfg: StalenessConfig{WarnHours: 10, StaleHours: 5}, err: "warn_hours (10) is after stale_hours (5)"},
+		{name: "warn after default stale", cfg: StalenessConfig{WarnHours: 30}, err: "warn_hours (30) is after stale_hours (24)"},
+		{name: "holiday out of range", cfg: StalenessConfig{Holidays: []string{"2026-13-01"}}, err: `holiday "2026-13-01" is not a YYYY-MM-DD date`},
+		{name: "holiday in another format", cfg: StalenessConfig{Holidays: []string{"24/12/2026"}}, err: `holiday "24/12/2026" is not a YYYY-MM-DD date`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := newAgePolicy(tt.cfg)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if policy.warn != tt.warn || policy.stale != tt.stale {
+				t.Fatalf("thresholds = %v/%v, want %v/%v", policy.warn, policy.stale, tt.warn, tt.stale)
+			}
+		})
+	}
+}
+
+func TestAgePolicyAge(t *testing.T) {
+	// Friday noon to Monday noon
+	friday := time.Date(2026, 10, 16, 12, 0, 0, 0, time.Local)
+	monday := time.Date(2026, 10, 19, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		name     string
+		cfg      StalenessConfig
+		from, to time.Time
+		want     time.Duration
+	}{
+		{name: "wall clock", from: friday, to: monday, want: 72 * time.Hour},
+		{name: "weekend skipped", cfg: StalenessConfig{SkipWeekends: true}, from: friday, to: monday, want: 24 * time.Hour},
+		{name: "weekend and holiday skipped", cfg: StalenessConfig{SkipWeekends: true, Holidays: []string{"2026-10-19"}}, from: friday, to: monday, want: 12 * time.Hour},
+		{name: "holiday only", cfg: StalenessConfig{Holidays: []string{"2026-10-17"}}, from: friday, to: monday, want: 48 * time.Hour},
+		{name: "unknown start", cfg: StalenessConfig{SkipWeekends: true}, to: monday, want: 0},
+		{name: "start in the future", from: monday, to: friday, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := newAgePolicy(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := policy.age(tt.from, tt.to); got != tt.want {
+				t.Fatalf("age = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAgePolicyStyle(t *testing.T) {
+	applyTheme(builtinThemes[defaultThemeName])
+	policy, err := newAgePolicy(StalenessConfig{WarnHours: 4, StaleHours: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The styles are told apart by their attributes, since colors depend on the theme
+	level := func(style lipgloss.Style) string {
+		switch {
+		case style.GetFaint():
+			return "fresh"
+		case style.GetBold():
+			return "stale"
+		}
+		return "warn"
+	}
+	tests := []struct {
+		age  time.Duration
+		want string
+	}{
+		{age: 0, want: "fresh"},
+		{age: 4*time.Hour - time.Minute, want: "fresh"},
+		{age: 4 * time.Hour, want: "warn"},
+		{age: 8*time.Hour - time.Minute, want: "warn"},
+		{age: 8 * time.Hour, want: "stale"},
+		{age: 72 * time.Hour, want: "stale"},
+	}
+	for _, tt := range tests {
+		if got := level(policy.style(tt.age)); got != tt.want {
+			t.Errorf("style(%v) = %s, want %s", tt.age, got, tt.want)
+		}
+	}
+}
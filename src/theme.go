@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"golang.org/x/term"
+)
+
+// Theme names the colors used by the TUI and CLI output, as ANSI numbers or hex values.
+// Empty fields in a user-defined theme fall back to the dark theme.
+type Theme struct {
+	PR        string `json:"pr"`
+	Draft     string `json:"draft"`
+	Reviewer  string `json:"reviewer"`
+	Required  string `json:"required"`
+	Vote      string `json:"vote"`
+	Title     string `json:"title"`
+	Muted     string `json:"muted"`
+	ToggleOn  string `json:"toggle_on"`
+	ToggleOff string `json:"toggle_off"`
+	Error     string `json:"error"`
+	DiffAdd   string `json:"diff_add"`
+	DiffDel   string `json:"diff_delete"`
+	DiffHunk  string `json:"diff_hunk"`
+	Keyword   string `json:"keyword"`
+	String    string `json:"string"`
+	Comment   string `json:"comment"`
+	Number    string `json:"number"`
+	CheckPass string `json:"check_pass"`
+	CheckFail string `json:"check_fail"`
+	CheckWait string `json:"check_pending"`
+}
+
+const defaultThemeName = "dark"
+
+var builtinThemes = map[string]Theme{
+	"dark": {
+		PR:        "2",
+		Draft:     "245",
+		Reviewer:  "5",
+		Required:  "1",
+		Vote:      "3",
+		Title:     "4",
+		Muted:     "8",
+		ToggleOn:  "2",
+		ToggleOff: "1",
+		Error:     "1",
+		DiffAdd:   "22",
+		DiffDel:   "52",
+		DiffHunk:  "6",
+		Keyword:   "5",
+		String:    "3",
+		Comment:   "245",
+		Number:    "6",
+		CheckPass: "2",
+		CheckFail: "1",
+		CheckWait: "3",
+	},
+	"light": {
+		PR:        "28",
+		Draft:     "242",
+		Reviewer:  "90",
+		Required:  "124",
+		Vote:      "130",
+		Title:     "18",
+		Muted:     "250",
+		ToggleOn:  "28",
+		ToggleOff: "124",
+		Error:     "160",
+		DiffAdd:   "194",
+		DiffDel:   "224",
+		DiffHunk:  "24",
+		Keyword:   "90",
+		String:    "130",
+		Comment:   "242",
+		Number:    "24",
+		CheckPass: "28",
+		CheckFail: "160",
+		CheckWait: "130",
+	},
+	"high-contrast": {
+		PR:        "10",
+		Draft:     "15",
+		Reviewer:  "13",
+		Required:  "9",
+		Vote:      "11",
+		Title:     "14",
+		Muted:     "7",
+		ToggleOn:  "10",
+		ToggleOff: "9",
+		Error:     "9",
+		DiffAdd:   "22",
+		DiffDel:   "88",
+		DiffHunk:  "14",
+		Keyword:   "13",
+		String:    "11",
+		Comment:   "7",
+		Number:    "14",
+		CheckPass: "10",
+		CheckFail: "9",
+		CheckWait: "11",
+	},
+}
+
+// Styles derived from the active theme, see applyTheme
+var (
+	prGreen           lipgloss.Style
+	draftGray         lipgloss.Style
+	reviewerName      lipgloss.Style
+	requiredStyle     lipgloss.Style
+	requiredYesStyle  lipgloss.Style
+	voteStyle         lipgloss.Style
+	requiredRowStyle  lipgloss.Style
+	titleStyle        lipgloss.Style
+	toggleOnStyle     lipgloss.Style
+	toggleOffStyle    lipgloss.Style
+	errorStyle        lipgloss.Style
+	diffAddBg         lipgloss.Color
+	diffDelBg         lipgloss.Color
+	diffHunkStyle     lipgloss.Style
+	keywordStyle      lipgloss.Style
+	stringStyle       lipgloss.Style
+	commentStyle      lipgloss.Style
+	numberStyle       lipgloss.Style
+	checkPassStyle    lipgloss.Style
+	checkFailStyle    lipgloss.Style
+	checkPendingStyle lipgloss.Style
+)
+
+// resolveTheme looks up a theme by name among the user-defined and built-in themes
+func resolveTheme(name string, custom map[string]Theme) (Theme, error) {
+	if name == "" {
+		name = defaultThemeName
+	}
+	base := builtinThemes[defaultThemeName]
+	if t, ok := custom[name]; ok {
+		return t.withFallback(base), nil
+	}
+	if t, ok := builtinThemes[name]; ok {
+		return t, nil
+	}
+	names := make([]string, 0, len(builtinThemes)+len(custom))
+	for n := range builtinThemes {
+		names = append(names, n)
+	}
+	for n := range custom {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return base, fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// withFallback fills the empty colors of t from base
+func (t Theme) withFallback(base Theme) Theme {
+	pick := func(c, fallback string) string {
+		if c == "" {
+			return fallback
+		}
+		return c
+	}
+	return Theme{
+		PR:        pick(t.PR, base.PR),
+		Draft:     pick(t.Draft, base.Draft),
+		Reviewer:  pick(t.Reviewer, base.Reviewer),
+		Required:  pick(t.Required, base.Required),
+		Vote:      pick(t.Vote, base.Vote),
+		Title:     pick(t.Title, base.Title),
+		Muted:     pick(t.Muted, base.Muted),
+		ToggleOn:  pick(t.ToggleOn, base.ToggleOn),
+		ToggleOff: pick(t.ToggleOff, base.ToggleOff),
+		Error:     pick(t.Error, base.Error),
+		DiffAdd:   pick(t.DiffAdd, base.DiffAdd),
+		DiffDel:   pick(t.DiffDel, base.DiffDel),
+		DiffHunk:  pick(t.DiffHunk, base.DiffHunk),
+		Keyword:   pick(t.Keyword, base.Keyword),
+		String:    pick(t.String, base.String),
+		Comment:   pick(t.Comment, base.Comment),
+		Number:    pick(t.Number, base.Number),
+		CheckPass: pick(t.CheckPass, base.CheckPass),
+		CheckFail: pick(t.CheckFail, base.CheckFail),
+		CheckWait: pick(t.CheckWait, base.CheckWait),
+	}
+}
+
+// applyTheme rebuilds the shared styles from the theme
+func applyTheme(t Theme) {
+	prGreen = lipgloss.NewStyle().Foreground(lipgloss.Color(t.PR))
+	draftGray = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Draft))
+	reviewerName = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(t.Reviewer)).Width(20)
+	requiredStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Required)).Bold(true).Width(9)
+	requiredYesStyle = requiredStyle.Foreground(lipgloss.Color(t.PR))
+	voteStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Vote)).Width(10)
+	requiredRowStyle = lipgloss.NewStyle().Background(lipgloss.Color(t.Muted)).Bold(true)
+	titleStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(t.Title)).Align(lipgloss.Center).MarginBottom(1).Height(2)
+	toggleOnStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.ToggleOn)).Bold(true)
+	toggleOffStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.ToggleOff)).Bold(true)
+	errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Error)).Bold(true)
+	diffAddBg = lipgloss.Color(t.DiffAdd)
+	diffDelBg = lipgloss.Color(t.DiffDel)
+	diffHunkStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.DiffHunk))
+	keywordStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Keyword))
+	stringStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.String))
+	commentStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Comment)).Italic(true)
+	numberStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Number))
+	checkPassStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.CheckPass))
+	checkFailStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.CheckFail)).Bold(true)
+	checkPendingStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.CheckWait))
+}
+
+// colorDisabled reports whether output should be plain, following the NO_COLOR
+// convention (https://no-color.org), the config and whether stdout is a terminal
+func colorDisabled(config Config) bool {
+	if os.Getenv("NO_COLOR") != "" || config.NoColor {
+		return true
+	}
+	return !term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// colorless reports whether styles render without color, so state has to be
+// shown some other way
+func colorless() bool {
+	return lipgloss.ColorProfile() == termenv.Ascii
+}
+
+// SetupTheme applies the configured theme and disables color when requested
+func SetupTheme(config Config) error {
+	if colorDisabled(config) {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+	theme, err := resolveTheme(config.Theme, config.Themes)
+	applyTheme(theme)
+	setLabelColors(config.LabelColors)
+	return err
+}
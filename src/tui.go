@@ -2,25 +2,22 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 var (
-	prGreen          = lipgloss.NewStyle().Foreground(lipgloss.Color("2")) // green
-	draftGray        = lipgloss.NewStyle().Foreground(lipgloss.Color("8")) // muted gray
-	selectedStyle    = lipgloss.NewStyle().Bold(true)
-	boxStyle         = lipgloss.NewStyle().Border(lipgloss.NormalBorder()).Padding(1, 2).Margin(0, 1)
-	reviewerBox      = lipgloss.NewStyle().Border(lipgloss.NormalBorder()).Padding(1, 2).Margin(1, 1)
-	reviewerName     = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("5")).Width(20)
-	requiredStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Bold(true).Width(9)
-	voteStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("3")).Width(10)
-	idStyle          = lipgloss.NewStyle().Faint(true).Width(24)
-	sepStyle         = lipgloss.NewStyle().Faint(true)
-	requiredRowStyle = lipgloss.NewStyle().Background(lipgloss.Color("8")).Bold(true)
-	titleStyle       = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("4")).Align(lipgloss.Center).MarginBottom(1).Height(2)
+	selectedStyle = lipgloss.NewStyle().Bold(true)
+	boxStyle      = lipgloss.NewStyle().Border(lipgloss.NormalBorder()).Padding(1, 2).Margin(0, 1)
+	reviewerBox   = lipgloss.NewStyle().Border(lipgloss.NormalBorder()).Padding(1, 2).Margin(1, 1)
+	idStyle       = lipgloss.NewStyle().Faint(true).Width(24)
+	sepStyle      = lipgloss.NewStyle().Faint(true)
 )
 
 func voteLabel(v int) string {
@@ -40,241 +37,778 @@ func voteLabel(v int) string {
 	}
 }
 
+// ignoredReviewerIDs are reviewers left out of the reviewer table and list and the digest
+var ignoredReviewerIDs = map[string]bool{
+	"1809cf47-1683-62b4-ab66-9dbfd3d291d6": true,
+	"59e23168-dd18-4b40-9065-f3182d63ff1a": true,
+}
+
+// statusMsg reports the outcome of a background action in the footer
+type statusMsg struct {
+	text string
+	err  error
+}
+
 type tuiModel struct {
 	prs             []PullRequestInfo
 	selected        int
 	showDrafts      bool
 	showMine        bool
 	showNotReviewer bool
+	showBlocking    bool
+	showUnlinked    bool
+	showDetail      bool
+	showHelp        bool
+	detailScroll    int
+	view            viewMode
+	files           filesState
+	diff            diffState
+	loadSeq         int // last files, diff or iterations load started, see nextLoad
+	iterations      iterationsState
+	checks          map[int]prChecks
+	conflicts       map[int]prConflicts
+	workItems       map[int]prWorkItems
+	workItemPicker  workItemsState
+	reviewers       reviewersState
+	filter          formField // query typed after the filter key, see matchesQuery
+	filtering       bool
+	statusMode      prStatusMode
+	loadingPRs      bool
+	historyDays     int // how far back closed PRs are listed
+	activity        map[int]prActivity
+	showInbox       bool
+	seen            seenStore
+	watch           *watcher // nil unless the TUI notifies about changes
+	watchInterval   time.Duration
+	notifiers       notifiers
+	ages            agePolicy
+	form            formState
+	session         *Session
+	status          statusMsg
+	keys            keyMap
+	help            help.Model
 	userID          string
 	width           int
 	height          int
 }
 
 func (m tuiModel) filteredPRs() []PullRequestInfo {
-	var filteredPRs []PullRequestInfo
-	seenPRs := make(map[int]bool)
+	if m.showInbox {
+		return m.inboxPRs()
+	}
+	return m.listFilter().apply(m.prs, func(prID int) bool { return m.checks[prID].blocking() }, m.unlinked)
+}
 
-	if m.showNotReviewer {
-		for _, pullRequest := range m.prs {
-			if !m.showDrafts && pullRequest.IsDraft {
-				continue
-			}
-			if !m.showMine && pullRequest.creatorID == m.userID {
-				continue
-			}
-			if !seenPRs[pullRequest.id] {
-				filteredPRs = append(filteredPRs, pullRequest)
-				seenPRs[pullRequest.id] = true
-			}
-		}
-		return filteredPRs
+// listFilter returns the filters currently toggled on the list
+func (m tuiModel) listFilter() prFilter {
+	return prFilter{
+		userID:      m.userID,
+		drafts:      m.showDrafts,
+		mine:        m.showMine,
+		notReviewer: m.showNotReviewer,
+		blocking:    m.showBlocking,
+		unlinked:    m.showUnlinked,
+		query:       m.filter.value(),
 	}
+}
 
-	for _, pullRequest := range m.prs {
-		if !m.showDrafts && pullRequest.IsDraft {
-			continue
-		}
-		if pullRequest.creatorID == m.userID && !m.showMine {
-			continue
-		}
-		isCurrentUserReviewer := false
-		for _, reviewer := range pullRequest.reviewers {
-			if reviewer.id == m.userID {
-				isCurrentUserReviewer = true
-				break
-			}
+// filterToggle identifies one of the list filters that can be switched on and off
+type filterToggle int
+
+const (
+	noToggle filterToggle = iota
+	toggleDrafts
+	toggleMine
+	toggleNotReviewer
+	toggleBlocking
+	toggleUnlinked
+	toggleInbox
+)
+
+// toggleFilter flips a list filter and moves the cursor back to the top
+func (m *tuiModel) toggleFilter(f filterToggle) {
+	switch f {
+	case toggleDrafts:
+		m.showDrafts = !m.showDrafts
+	case toggleMine:
+		m.showMine = !m.showMine
+	case toggleNotReviewer:
+		m.showNotReviewer = !m.showNotReviewer
+	case toggleBlocking:
+		m.showBlocking = !m.showBlocking
+	case toggleUnlinked:
+		m.showUnlinked = !m.showUnlinked
+	case toggleInbox:
+		m.showInbox = !m.showInbox
+	default:
+		return
+	}
+	m.selected = 0
+	m.detailScroll = 0
+}
+
+// moveSelection moves the cursor by delta PRs, staying within the filtered list
+func (m *tuiModel) moveSelection(delta int) {
+	next := max(0, min(m.selected+delta, len(m.filteredPRs())-1))
+	if next != m.selected {
+		m.selected = next
+		m.detailScroll = 0
+	}
+}
+
+// selectedPR returns the PR under the cursor, if any
+func (m tuiModel) selectedPR() (PullRequestInfo, bool) {
+	prs := m.filteredPRs()
+	if m.selected < 0 || m.selected >= len(prs) {
+		return PullRequestInfo{}, false
+	}
+	return prs[m.selected], true
+}
+
+// reselect moves the cursor back onto the PR with the given id after the list
+// changed, keeping it in range when that PR is no longer listed
+func (m *tuiModel) reselect(id int, ok bool) {
+	prs := m.filteredPRs()
+	for i, pr := range prs {
+		if ok && pr.id == id {
+			m.selected = i
+			return
 		}
-		if isCurrentUserReviewer && !seenPRs[pullRequest.id] {
-			filteredPRs = append(filteredPRs, pullRequest)
-			seenPRs[pullRequest.id] = true
+	}
+	m.selected = max(0, min(m.selected, len(prs)-1))
+}
+
+func openBrowserCmd(url string) tea.Cmd {
+	return func() tea.Msg {
+		if err := OpenBrowser(url); err != nil {
+			return statusMsg{err: fmt.Errorf("could not open browser: %w", err)}
 		}
+		return statusMsg{text: "Opened " + url}
 	}
+}
 
-	if m.showMine {
-		for _, pullRequest := range m.prs {
-			if pullRequest.creatorID == m.userID && !pullRequest.IsDraft && !seenPRs[pullRequest.id] {
-				filteredPRs = append(filteredPRs, pullRequest)
-				seenPRs[pullRequest.id] = true
-			}
-			if m.showDrafts && pullRequest.creatorID == m.userID && pullRequest.IsDraft && !seenPRs[pullRequest.id] {
-				filteredPRs = append(filteredPRs, pullRequest)
-				seenPRs[pullRequest.id] = true
-			}
+func copyCmd(text string) tea.Cmd {
+	return func() tea.Msg {
+		if text == "" {
+			return statusMsg{err: fmt.Errorf("nothing to copy")}
+		}
+		if err := CopyToClipboard(text); err != nil {
+			return statusMsg{err: fmt.Errorf("could not copy to clipboard: %w", err)}
 		}
+		return statusMsg{text: "Copied " + text}
 	}
-	return filteredPRs
 }
 
 func (m tuiModel) Init() tea.Cmd {
-	return tea.EnterAltScreen
+	cmds := []tea.Cmd{tea.EnterAltScreen, loadChecksCmd(m.session, m.prs), loadConflictsCmd(m.session, m.prs), loadWorkItemsCmd(m.session, m.prs), loadActivityCmd(m.session, m.userID, m.prs)}
+	if m.watch != nil {
+		cmds = append(cmds, m.pollWatchCmd())
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case statusMsg:
+		m.status = msg
+	case tea.MouseMsg:
+		return m.handleMouse(msg), nil
+	case filesLoadedMsg:
+		if msg.seq == m.files.seq {
+			m.files.loading = false
+			m.files.iteration = msg.iteration
+			m.files.compareTo = msg.compareTo
+			m.files.changes = msg.changes
+			m.files.err = msg.err
+		}
+	case checksLoadedMsg:
+		m.checks[msg.prID] = prChecks{items: msg.checks, err: msg.err}
+		// A filtered list can shrink as checks arrive
+		m.selected = max(0, min(m.selected, len(m.filteredPRs())-1))
+	case watchTickMsg:
+		return m, m.pollWatchCmd()
+	case watchPolledMsg:
+		return m.watchPolled(msg)
+	case activityLoadedMsg:
+		if msg.err == nil {
+			// The inbox lists and orders PRs by their activity
+			selected, ok := m.selectedPR()
+			m.activity[msg.prID] = msg.activity
+			m.reselect(selected.id, ok)
+		}
+		m = m.activityLoaded(msg.prID, msg.err)
+	case workItemsLoadedMsg:
+		m.workItems[msg.prID] = prWorkItems{items: msg.items, err: msg.err}
+		m.selected = max(0, min(m.selected, len(m.filteredPRs())-1))
+	case formDoneMsg:
+		m = m.formDone(msg)
+		switch {
+		case msg.err != nil && msg.kind == formLabels:
+			// Labels changed before the failure are kept, and a retry starts from them
+			m.replacePR(msg.pr)
+			if m.view == viewForm && m.form.kind == formLabels && m.form.pr.id == msg.pr.id {
+				m.form.pr = msg.pr
+			}
+		case msg.err != nil:
+		case msg.kind == formCreate:
+			m.prs = append(m.prs, msg.pr)
+			created := []PullRequestInfo{msg.pr}
+			return m, tea.Batch(loadChecksCmd(m.session, created), loadConflictsCmd(m.session, created), loadWorkItemsCmd(m.session, created), loadActivityCmd(m.session, m.userID, created))
+		case !m.statusMode.lists(msg.pr.status):
+			// Completing, abandoning or reactivating moves the PR to another status
+			m.removePR(msg.pr.id)
+		default:
+			m.replacePR(msg.pr)
+		}
+	case checkoutDetectedMsg:
+		m = m.checkoutDetected(msg)
+	case editorDoneMsg:
+		if m.view == viewForm && msg.field < len(m.form.fields) {
+			if msg.err != nil {
+				m.form.err = msg.err
+			} else {
+				m = m.clearPreview()
+				m.form.fields[msg.field] = textField(m.form.fields[msg.field].label, msg.text)
+				m.form.fields[msg.field].multiline = true
+			}
+		}
+	case identitySearchMsg:
+		if m.view == viewReviewers && m.reviewers.picking && msg.seq == m.reviewers.seq && m.reviewers.query.value() != "" {
+			m.reviewers.searching = true
+			return m, m.searchIdentitiesCmd()
+		}
+	case identitiesFoundMsg:
+		if msg.seq == m.reviewers.seq {
+			m.reviewers.searching = false
+			m.reviewers.found = msg.items
+			m.reviewers.err = msg.err
+		}
+	case prsLoadedMsg:
+		return m.setPRs(msg)
+	case editLoadedMsg:
+		if msg.err != nil {
+			m.status = statusMsg{err: msg.err}
+		} else if m.view == viewPRs {
+			m.status = statusMsg{}
+			m = m.openEditForm(msg.pr)
+		}
+	case reviewersUpdatedMsg:
+		m.setReviewers(msg)
+	case conflictsLoadedMsg:
+		m.conflicts[msg.prID] = prConflicts{paths: msg.paths, err: msg.err}
+	case iterationsLoadedMsg:
+		if msg.seq == m.iterations.seq {
+			m.iterations.loading = false
+			m.iterations.items = msg.items
+			m.iterations.voted = msg.voted
+			m.iterations.err = msg.err
+			// Start on the newest push
+			m.iterations.selected = max(0, len(msg.items)-1)
+		}
+	case diffLoadedMsg:
+		if msg.seq == m.diff.seq {
+			m.diff.loading = false
+			m.diff.diff = msg.diff
+			m.diff.err = msg.err
+		}
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "up", "k":
-			if m.selected > 0 {
-				m.selected--
+		m.status = statusMsg{}
+		if m.showHelp {
+			// Any help or quit key closes the overlay
+			if key.Matches(msg, m.keys.Help, m.keys.Quit) {
+				m.showHelp = false
+			}
+			return m, nil
+		}
+		switch m.view {
+		case viewFiles:
+			return m.updateFiles(msg)
+		case viewDiff:
+			return m.updateDiff(msg)
+		case viewIterations:
+			return m.updateIterations(msg)
+		case viewWorkItems:
+			return m.updateWorkItems(msg)
+		case viewForm:
+			return m.updateForm(msg)
+		case viewReviewers:
+			return m.updateReviewers(msg)
+		}
+		if m.filtering {
+			return m.updateFilter(msg)
+		}
+		switch {
+		case key.Matches(msg, m.keys.Up):
+			m.moveSelection(-1)
+		case key.Matches(msg, m.keys.Down):
+			m.moveSelection(1)
+		case key.Matches(msg, m.keys.ToggleDrafts):
+			m.toggleFilter(toggleDrafts)
+		case key.Matches(msg, m.keys.ToggleMine):
+			m.toggleFilter(toggleMine)
+		case key.Matches(msg, m.keys.ToggleNotReviewer):
+			m.toggleFilter(toggleNotReviewer)
+		case key.Matches(msg, m.keys.ToggleBlocking):
+			m.toggleFilter(toggleBlocking)
+		case key.Matches(msg, m.keys.ToggleUnlinked):
+			m.toggleFilter(toggleUnlinked)
+		case key.Matches(msg, m.keys.Inbox):
+			m.toggleFilter(toggleInbox)
+		case key.Matches(msg, m.keys.MarkRead):
+			if pr, ok := m.selectedPR(); ok {
+				m = m.markRead(pr)
+			}
+		case key.Matches(msg, m.keys.MarkAllRead):
+			m = m.markRead(m.prs...)
+		case key.Matches(msg, m.keys.Details):
+			// Only the compact layout hides the detail pane
+			m.showDetail = !m.showDetail
+		case key.Matches(msg, m.keys.OpenBrowser):
+			if pr, ok := m.selectedPR(); ok {
+				return m, openBrowserCmd(pr.url)
+			}
+		case key.Matches(msg, m.keys.CopyURL):
+			if pr, ok := m.selectedPR(); ok {
+				return m, copyCmd(pr.url)
+			}
+		case key.Matches(msg, m.keys.CopyRef):
+			if pr, ok := m.selectedPR(); ok {
+				return m, copyCmd(fmt.Sprintf("!%d", pr.id))
 			}
-		case "down", "j":
-			if m.selected < len(m.filteredPRs())-1 {
-				m.selected++
+		case key.Matches(msg, m.keys.Files):
+			return m.openFiles()
+		case key.Matches(msg, m.keys.Complete):
+			return m.openCompleteForm()
+		case key.Matches(msg, m.keys.AutoComplete):
+			return m.openAutoCompleteForm()
+		case key.Matches(msg, m.keys.Create):
+			return m.openCreateForm()
+		case key.Matches(msg, m.keys.Reviewers):
+			return m.openReviewers()
+		case key.Matches(msg, m.keys.Edit):
+			return m.openEdit()
+		case key.Matches(msg, m.keys.Labels):
+			return m.openLabelsForm()
+		case key.Matches(msg, m.keys.StatusMode):
+			return m.switchStatus()
+		case key.Matches(msg, m.keys.Filter):
+			m.filtering = true
+		case key.Matches(msg, m.keys.Draft):
+			return m.openDraftForm()
+		case key.Matches(msg, m.keys.Abandon):
+			return m.openAbandonForm()
+		case key.Matches(msg, m.keys.WorkItems):
+			return m.openWorkItems()
+		case key.Matches(msg, m.keys.Iterations):
+			return m.openIterations()
+		case key.Matches(msg, m.keys.SinceVote):
+			if pr, ok := m.selectedPR(); ok {
+				return m.openChangesSinceVote(pr)
+			}
+		case key.Matches(msg, m.keys.Help):
+			m.showHelp = true
+		case key.Matches(msg, m.keys.Quit):
+			if m.showDetail && chooseLayout(m.width) == layoutCompact {
+				m.showDetail = false
+				return m, nil
 			}
-		case "d":
-			m.showDrafts = !m.showDrafts
-			m.selected = 0
-		case "m":
-			m.showMine = !m.showMine
-			m.selected = 0
-		case "r":
-			m.showNotReviewer = !m.showNotReviewer
-			m.selected = 0
-		case "q", "esc", "ctrl+c":
 			return m, tea.Quit
 		}
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		m.help.Width = msg.Width
 	}
 	return m, nil
 }
 
 func (m tuiModel) View() string {
+	view, _ := m.render()
+	return view
+}
+
+// render draws the screen and records where each pane ended up, so mouse events
+// can be mapped back to PRs and toggles using the same geometry that was drawn
+func (m tuiModel) render() (string, screenLayout) {
+	var layout screenLayout
 	prs := m.filteredPRs()
-	// Check if all non-draft PRs are mine
-	nonDraftCount := 0
-	mineCount := 0
-	for _, pr := range m.prs {
-		if !pr.IsDraft {
-			nonDraftCount++
-			if pr.creatorID == m.userID {
-				mineCount++
-			}
-		}
-	}
+	mode := chooseLayout(m.width)
+	bodyHeight := m.height - footerHeight
 	mainArea := ""
-	if len(prs) == 0 {
-		msg := "No open pull requests where you are set as a reviewer."
+	if m.showHelp {
+		mainArea = m.renderHelp(bodyHeight)
+	} else if m.view == viewFiles {
+		files, filesPane := m.renderFiles(m.width, bodyHeight)
+		layout.files = filesPane
+		mainArea = files
+	} else if m.view == viewDiff {
+		diff, diffPane := m.renderDiff(m.width, bodyHeight)
+		layout.diff = diffPane
+		mainArea = diff
+	} else if m.view == viewIterations {
+		iterations, iterationsPane := m.renderIterations(m.width, bodyHeight)
+		layout.iterations = iterationsPane
+		mainArea = iterations
+	} else if m.view == viewForm {
+		mainArea = m.renderForm(m.width, bodyHeight)
+	} else if m.view == viewReviewers {
+		reviewers, reviewersPane := m.renderReviewers(m.width, bodyHeight)
+		layout.reviewers = reviewersPane
+		mainArea = reviewers
+	} else if m.view == viewWorkItems {
+		workItems, workItemsPane := m.renderWorkItemPicker(m.width, bodyHeight)
+		layout.workItems = workItemsPane
+		mainArea = workItems
+	} else if len(prs) == 0 {
+		msg := fmt.Sprintf("No %s where you are set as a reviewer.", m.statusDescription())
 		if m.showNotReviewer {
-			msg = "No open pull requests where you are NOT set as a reviewer."
+			msg = fmt.Sprintf("No %s where you are NOT set as a reviewer.", m.statusDescription())
+		}
+		if m.showBlocking {
+			msg = "No pull requests with blocking policies."
+		}
+		if m.showUnlinked {
+			msg = "No pull requests without linked work items."
 		}
-		mainArea = lipgloss.NewStyle().Width(m.width).Height(m.height-2).Align(lipgloss.Center, lipgloss.Center).Render(msg)
+		if m.filter.value() != "" {
+			msg = fmt.Sprintf("No pull requests match %q.", m.filter.value())
+		}
+		if m.showInbox {
+			msg = "Nothing needs your attention."
+		}
+		if m.loadingPRs {
+			msg = fmt.Sprintf("Loading %s...", m.statusDescription())
+		}
+		mainArea = lipgloss.NewStyle().Width(m.width).Height(bodyHeight).Align(lipgloss.Center, lipgloss.Center).Render(msg)
 	} else {
-		// Calculate max width for the PR line inside the box
-		frameWidth, _ := boxStyle.GetFrameSize()
-		maxBoxWidth := m.width / 2
-		usableWidth := maxBoxWidth - frameWidth
-		prLines := make([]string, len(prs))
-		for i, pr := range prs {
-			cursor := " "
-			if i == m.selected {
-				cursor = ">"
-			}
-			mode := ""
-			if pr.IsDraft {
-				mode = "[Draft] "
-			}
-			idStr := fmt.Sprintf("[%d]", pr.id)
-			creatorStr := fmt.Sprintf("(by %s)", pr.creator)
-			staticLen := len(cursor) + 1 + len(idStr) + 1 + len(mode) + 1 + len(creatorStr) + 1 // spaces between
-			maxTitleLen := usableWidth - staticLen
-			title := pr.title
-			if maxTitleLen <= 0 {
-				title = ""
-			} else if len(title) > maxTitleLen {
-				title = title[:maxTitleLen-3] + "..."
-			}
-			prLine := fmt.Sprintf("%s %s %s%s %s", cursor, idStr, mode, title, creatorStr)
-			if len(prLine) > usableWidth {
-				// Cut off from the right, but always keep ID and creator
-				cutLen := usableWidth - len(creatorStr) - 1 // space before creatorStr
-				if cutLen > 0 {
-					prLine = prLine[:cutLen] + " " + creatorStr
-				} else {
-					prLine = cursor + " " + idStr + " " + creatorStr
-				}
+		selectedPR := prs[m.selected]
+		listWidth, detailWidth := paneSizes(mode, m.width)
+		switch mode {
+		case layoutWide:
+			list, listPane := m.renderPRList(prs, listWidth, bodyHeight)
+			detail, detailPane := m.renderDetail(selectedPR, detailWidth, bodyHeight)
+			layout.list = listPane.at(0, 0)
+			layout.detail = detailPane.at(lipgloss.Width(list), 0)
+			mainArea = lipgloss.JoinHorizontal(lipgloss.Top, list, detail)
+		case layoutStacked:
+			half := bodyHeight / 2
+			list, listPane := m.renderPRList(prs, listWidth, half)
+			top := lipgloss.Place(m.width, half, lipgloss.Center, lipgloss.Center, list)
+			detailHeight := bodyHeight - lipgloss.Height(top) - 1
+			detail, detailPane := m.renderDetail(selectedPR, detailWidth, detailHeight)
+			layout.list = listPane.at(centerOffset(m.width, lipgloss.Width(list)), centerOffset(half, lipgloss.Height(list)))
+			layout.detail = detailPane.at(centerOffset(m.width, lipgloss.Width(detail)), lipgloss.Height(top))
+			mainArea = top + "\n" + lipgloss.Place(m.width, detailHeight, lipgloss.Center, lipgloss.Top, detail)
+		default:
+			if m.showDetail {
+				detail, detailPane := m.renderDetail(selectedPR, detailWidth, bodyHeight)
+				layout.detail = detailPane.at(0, 0)
+				mainArea = detail
+			} else {
+				list, listPane := m.renderPRList(prs, listWidth, bodyHeight)
+				layout.list = listPane.at(0, 0)
+				mainArea = list
 			}
+		}
+	}
+	footer, toggles := m.renderFooter(mode)
+	footerY := lipgloss.Height(mainArea)
+	for _, t := range toggles {
+		layout.toggles = append(layout.toggles, t.at(0, footerY))
+	}
+	return mainArea + "\n" + footer, layout
+}
+
+// listBadges renders the status badges and labels shown before a PR title in a
+// list row of the given width, returning them with their width
+func listBadges(pr PullRequestInfo, width int) (string, int) {
+	var styled, plain string
+	add := func(style lipgloss.Style, badge string) {
+		if badge != "" {
+			styled += style.Render(badge) + " "
+			plain += badge + " "
+		}
+	}
+	switch pr.status {
+	case "abandoned":
+		add(checkFailStyle, "[Abandoned]")
+	case "completed":
+		add(checkPassStyle, "[Merged "+pr.closedDate.Local().Format("Jan 2")+"]")
+	}
+	add(checkFailStyle, mergeBadge(pr.mergeStatus))
+	if pr.autoCompleteSetByID != "" {
+		add(checkPassStyle, "[Auto-complete]")
+	}
+	// Labels get at most a third of the row so the title stays readable
+	if chips, chipsWidth := labelChips(pr.labels, max(2, width/3)); chipsWidth > 0 {
+		return styled + chips + " ", lipgloss.Width(plain) + chipsWidth + 1
+	}
+	return styled, lipgloss.Width(plain)
+}
+
+// renderPRList renders the PR list box, scrolling so the selection stays visible
+func (m tuiModel) renderPRList(prs []PullRequestInfo, width, height int) (string, paneLayout) {
+	frameWidth, frameHeight := boxStyle.GetFrameSize()
+	usableWidth := width - frameWidth
+	visible := height - frameHeight
+	if visible < 1 {
+		visible = 1
+	}
+	first := 0
+	if m.selected >= visible {
+		first = m.selected - visible + 1
+	}
+	last := min(len(prs), first+visible)
+	prLines := make([]string, 0, last-first)
+	for i := first; i < last; i++ {
+		pr := prs[i]
+		cursor := " "
+		if i == m.selected {
+			cursor = ">"
+		}
+		checks := m.checksIndicator(pr.id)
+		unseen := len(m.unseenChanges(pr)) > 0
+		if pr.closed() {
+			checks = " "
+		}
+		mode := ""
+		if pr.IsDraft {
+			mode = "[Draft] "
+		}
+		idStr := fmt.Sprintf("[%d]", pr.id)
+		creatorStr := fmt.Sprintf("(by %s)", pr.creator)
+		if usableWidth < stackedMinWidth/2 {
+			// Narrow panes drop the creator to leave room for the title
+			creatorStr = ""
+		}
+		badges, badgesWidth := listBadges(pr, usableWidth)
+		if reasons := m.attention(pr); m.showInbox && len(reasons) > 0 {
+			reason := "[" + reasons[0].String() + "]"
+			badges = checkPendingStyle.Render(reason) + " " + badges
+			badgesWidth += lipgloss.Width(reason) + 1
+		}
+		// Ages line up in a column at least four wide
+		age := m.ageIndicator(pr)
+		age += strings.Repeat(" ", max(1, 4-lipgloss.Width(age)))
+		ageWidth := lipgloss.Width(age)
+		// The cursor and the checks indicator take the first four columns
+		staticLen := len(cursor) + 3 + len(idStr) + 1 + ageWidth + badgesWidth + len(mode) + 1 + len(creatorStr) // spaces between
+		title := truncate(pr.title, usableWidth-staticLen)
+		rest := strings.TrimRight(fmt.Sprintf("%s%s %s", mode, title, creatorStr), " ")
+		rest = truncate(rest, max(0, usableWidth-4-len(idStr)-1-ageWidth-badgesWidth))
+		paint := func(s string) string {
+			style := prGreen
 			if pr.IsDraft {
-				prLine = draftGray.Render(prLine)
-			} else {
-				prLine = prGreen.Render(prLine)
+				style = draftGray
 			}
+			s = style.Bold(unseen).Render(s)
 			if i == m.selected {
-				prLine = selectedStyle.Render(prLine)
+				s = selectedStyle.Render(s)
 			}
-			prLines[i] = prLine
+			return s
 		}
-		prBox := boxStyle.Width(maxBoxWidth).Align(lipgloss.Left).Render(lipgloss.JoinVertical(lipgloss.Left, prLines...))
-		mainArea += lipgloss.Place(m.width, m.height/2, lipgloss.Center, lipgloss.Center, prBox)
-		selectedPR := prs[m.selected]
-		// Title area (big, centered)
-		titleArea := titleStyle.Render(selectedPR.title)
-		// Reviewer table area
-		reviewerLines := []string{"Reviewers:",
-			sepStyle.Render("┌" + strings.Repeat("─", 20) + "┬" + strings.Repeat("─", 9) + "┬" + strings.Repeat("─", 10) + "┬" + strings.Repeat("─", 24) + "┐"),
-			"│" + reviewerName.Render("Name") + "│" + requiredStyle.Render("Required") + "│" + voteStyle.Render("Vote") + "│" + idStyle.Render("ID") + "│",
-			sepStyle.Render("├" + strings.Repeat("─", 20) + "┼" + strings.Repeat("─", 9) + "┼" + strings.Repeat("─", 10) + "┼" + strings.Repeat("─", 24) + "┤"),
-		}
-		ignoreIDs := map[string]bool{
-			"1809cf47-1683-62b4-ab66-9dbfd3d291d6": true,
-			"59e23168-dd18-4b40-9065-f3182d63ff1a": true,
-		}
-		for _, rev := range selectedPR.reviewers {
-			if ignoreIDs[rev.id] {
-				continue
-			}
-			nameStr := rev.displayName
-			maxNameLen := 20
-			if len(nameStr) > maxNameLen {
-				nameStr = nameStr[:maxNameLen-3] + "..."
-			}
-			name := reviewerName.Render(nameStr)
-			required := requiredStyle.Render("")
-			if rev.isRequired {
-				required = requiredStyle.Foreground(lipgloss.Color("2")).Render("✔ Yes")
-			}
-			vote := voteStyle.Render(voteLabel(rev.vote))
-			idStr := rev.id
-			maxIdLen := 24
-			if len(idStr) > maxIdLen {
-				idStr = idStr[:maxIdLen-3] + "..."
+		prLine := paint(cursor+" ") + checks + paint(" "+idStr+" ") + age + badges + paint(rest)
+		prLines = append(prLines, prLine)
+	}
+	box := boxStyle.Width(width - boxStyle.GetHorizontalMargins() - boxStyle.GetHorizontalBorderSize()).Align(lipgloss.Left).Render(lipgloss.JoinVertical(lipgloss.Left, prLines...))
+	pane := paneLayout{
+		area:    rect{w: lipgloss.Width(box), h: lipgloss.Height(box)},
+		content: rect{x: boxStyle.GetMarginLeft() + boxStyle.GetBorderLeftSize() + boxStyle.GetPaddingLeft(), y: boxStyle.GetBorderTopSize() + boxStyle.GetPaddingTop(), w: usableWidth, h: last - first},
+		first:   first,
+	}
+	return box, pane
+}
+
+// renderDetail renders the title and reviewer table of the selected PR, scrolled by
+// detailScroll and clipped to height when the height is known
+func (m tuiModel) renderDetail(pr PullRequestInfo, width, height int) (string, paneLayout) {
+	boxWidth := width - reviewerBox.GetHorizontalMargins() - reviewerBox.GetHorizontalBorderSize()
+	innerWidth := boxWidth - reviewerBox.GetHorizontalPadding()
+	// Title area (big, centered)
+	titleArea := titleStyle.Width(innerWidth).Render(pr.title)
+	extra := m.renderAge(pr, innerWidth)
+	if len(pr.labels) > 0 {
+		chips, _ := labelChips(pr.labels, 0)
+		extra = append(extra, lipgloss.NewStyle().Width(innerWidth).Render(chips))
+	}
+	if len(extra) > 0 {
+		titleArea += "\n" + strings.Join(extra, "\n") + "\n"
+	}
+	// Reviewer table area
+	cols := layoutReviewerColumns(innerWidth)
+	cell := func(style lipgloss.Style, w int, s string) string {
+		if w == 0 {
+			return ""
+		}
+		return style.Width(w).MaxWidth(w).Render(truncate(s, w)) + "│"
+	}
+	header := "│" + cell(reviewerName, cols.name, "Name") + cell(requiredStyle, cols.required, requiredLabel(cols.required)) +
+		cell(voteStyle, cols.vote, "Vote") + cell(idStyle, cols.id, "ID")
+	reviewerLines := []string{"Reviewers:",
+		sepStyle.Render(cols.border("┌", "┬", "┐")),
+		header,
+		sepStyle.Render(cols.border("├", "┼", "┤")),
+	}
+	for _, rev := range listedReviewers(pr) {
+		required := cell(requiredStyle, cols.required, "")
+		if rev.isRequired {
+			yes := "✔ Yes"
+			if cols.required < requiredColMax {
+				yes = "✔"
 			}
-			id := idStyle.Render(idStr)
-			row := "│" + name + "│" + required + "│" + vote + "│" + id + "│"
-			reviewerLines = append(reviewerLines, row)
+			required = cell(requiredYesStyle, cols.required, yes)
 		}
-		reviewerLines = append(reviewerLines, sepStyle.Render("└"+strings.Repeat("─", 20)+"┴"+strings.Repeat("─", 9)+"┴"+strings.Repeat("─", 10)+"┴"+strings.Repeat("─", 24)+"┘"))
-		// Combine title and reviewers in the box
-		reviewerBoxStr := reviewerBox.Width(maxBoxWidth).Align(lipgloss.Left).Render(
-			titleArea + "\n" + lipgloss.JoinVertical(lipgloss.Left, reviewerLines...))
-		mainArea += "\n" + lipgloss.Place(m.width, m.height/2, lipgloss.Center, lipgloss.Top, reviewerBoxStr)
+		row := "│" + cell(reviewerName, cols.name, rev.displayName) + required +
+			cell(voteStyle, cols.vote, voteLabel(rev.vote)) + cell(idStyle, cols.id, rev.id)
+		reviewerLines = append(reviewerLines, row)
 	}
-	// Instructions at the bottom
+	reviewerLines = append(reviewerLines, sepStyle.Render(cols.border("└", "┴", "┘")), "")
+	reviewerLines = append(reviewerLines, m.renderSeen(pr, innerWidth)...)
+	reviewerLines = append(reviewerLines, m.renderAttention(pr, innerWidth)...)
+	if !pr.closed() {
+		reviewerLines = append(reviewerLines, m.renderChecks(pr.id, innerWidth)...)
+		reviewerLines = append(reviewerLines, "")
+	}
+	reviewerLines = append(reviewerLines, m.renderMerge(pr, innerWidth)...)
+	reviewerLines = append(reviewerLines, "")
+	reviewerLines = append(reviewerLines, m.renderWorkItems(pr.id, innerWidth)...)
+	// Combine title and reviewers in the box
+	lines := strings.Split(titleArea+"\n"+lipgloss.JoinVertical(lipgloss.Left, reviewerLines...), "\n")
+	maxScroll := 0
+	if visible := height - reviewerBox.GetVerticalFrameSize(); height > 0 && visible > 0 && len(lines) > visible {
+		maxScroll = len(lines) - visible
+		scroll := min(m.detailScroll, maxScroll)
+		lines = lines[scroll : scroll+visible]
+	}
+	box := reviewerBox.Width(boxWidth).Align(lipgloss.Left).Render(strings.Join(lines, "\n"))
+	pane := paneLayout{
+		area:      rect{w: lipgloss.Width(box), h: lipgloss.Height(box)},
+		maxScroll: maxScroll,
+	}
+	return box, pane
+}
+
+// requiredLabel returns the Required column header for the given column width
+func requiredLabel(width int) string {
+	if width < requiredColMax {
+		return "Req"
+	}
+	return "Required"
+}
+
+// renderFooter renders the short help bar, colouring the filter toggles by their state,
+// or marking them [x] and [ ] when there is no color.
+// It also returns where the filter toggles were drawn, relative to the footer.
+func (m tuiModel) renderFooter(mode layoutMode) (string, []toggleHit) {
 	menuBox := lipgloss.NewStyle().Border(lipgloss.NormalBorder()).Padding(0, 2)
-	// Styles for ON/OFF
-	onStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("2")).Bold(true)  // green
-	offStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Bold(true) // red
+	toggle := func(b key.Binding, on bool) key.Binding {
+		style, marker := toggleOffStyle, "[ ] "
+		if on {
+			style, marker = toggleOnStyle, "[x] "
+		}
+		if !colorless() {
+			// The color alone tells on from off
+			marker = ""
+		}
+		b.SetHelp(style.Render(marker+b.Help().Key), b.Help().Desc)
+		return b
+	}
 
-	dKey := offStyle.Render("d")
-	if m.showDrafts {
-		dKey = onStyle.Render("d")
+	bindings := []key.Binding{m.keys.Up, m.keys.Down}
+	filters := []filterToggle{noToggle, noToggle}
+	if mode == layoutCompact {
+		// Keep help reachable when the bar is truncated
+		bindings = []key.Binding{m.keys.Help, m.keys.Details, m.keys.Up, m.keys.Down}
+		filters = []filterToggle{noToggle, noToggle, noToggle, noToggle}
 	}
-	mKey := offStyle.Render("m")
-	if m.showMine {
-		mKey = onStyle.Render("m")
+	bindings = append(bindings,
+		toggle(m.keys.Inbox, m.showInbox),
+		toggle(m.keys.ToggleDrafts, m.showDrafts),
+		toggle(m.keys.ToggleMine, m.showMine),
+		toggle(m.keys.ToggleNotReviewer, m.showNotReviewer),
+		toggle(m.keys.ToggleBlocking, m.showBlocking),
+		toggle(m.keys.ToggleUnlinked, m.showUnlinked),
+	)
+	filters = append(filters, toggleInbox, toggleDrafts, toggleMine, toggleNotReviewer, toggleBlocking, toggleUnlinked)
+	if mode != layoutCompact {
+		bindings = append(bindings, m.keys.Help)
 	}
-	rKey := offStyle.Render("r")
-	if m.showNotReviewer {
-		rKey = onStyle.Render("r")
+	bindings = append(bindings, m.keys.Quit)
+	relabel := func(b key.Binding, desc string) key.Binding {
+		b.SetHelp(b.Help().Key, desc)
+		return b
 	}
-
-	instructions := fmt.Sprintf("  ↑/↓ to navigate | %s: toggle drafts | %s: show/hide your own PRs | %s: show PRs where you are NOT a reviewer | q: quit  ", dKey, mKey, rKey)
+	switch m.view {
+	case viewFiles:
+		bindings = []key.Binding{m.keys.Help, m.keys.Up, m.keys.Down, relabel(m.keys.Details, "open diff"), m.keys.OpenBrowser, relabel(m.keys.Quit, "back")}
+		filters = make([]filterToggle, len(bindings))
+	case viewForm:
+		bindings = []key.Binding{formNext, formPrev, formChange, formSubmit, formCancel}
+		if f := m.form; f.focus < len(f.fields) && f.fields[f.focus].multiline {
+			bindings = append(bindings, formEditor)
+		}
+		filters = make([]filterToggle, len(bindings))
+	case viewWorkItems:
+		bindings = []key.Binding{m.keys.Help, m.keys.Up, m.keys.Down, relabel(m.keys.Details, "open in browser"), relabel(m.keys.Quit, "back")}
+		filters = make([]filterToggle, len(bindings))
+	case viewReviewers:
+		bindings = []key.Binding{m.keys.Help, m.keys.Up, m.keys.Down, m.keys.AddReviewer, m.keys.RemoveReviewer, m.keys.ToggleRequired, relabel(m.keys.Quit, "back")}
+		if m.reviewers.picking {
+			bindings = []key.Binding{pickerMove, pickerChoose, formCancel}
+		}
+		filters = make([]filterToggle, len(bindings))
+	case viewIterations:
+		bindings = []key.Binding{m.keys.Help, m.keys.Up, m.keys.Down, relabel(m.keys.Details, "files in push"), m.keys.SinceVote, relabel(m.keys.Quit, "back")}
+		filters = make([]filterToggle, len(bindings))
+	case viewDiff:
+		bindings = []key.Binding{m.keys.Help, m.keys.Up, m.keys.Down, m.keys.NextHunk, m.keys.PrevHunk, m.keys.SideBySide, m.keys.PageDown, relabel(m.keys.Quit, "back")}
+		filters = make([]filterToggle, len(bindings))
+	}
+	h := m.help
+	if m.width > 0 {
+		h.Width = m.width - menuBox.GetHorizontalFrameSize()
+	}
+	if query := m.filter.value(); query != "" && m.view == viewPRs && !m.filtering {
+		bindings = append([]key.Binding{relabel(m.keys.Filter, query)}, bindings...)
+		filters = append([]filterToggle{noToggle}, filters...)
+	}
+	if m.statusMode != statusActive && m.view == viewPRs {
+		bindings = append([]key.Binding{relabel(m.keys.StatusMode, m.statusMode.String())}, bindings...)
+		filters = append([]filterToggle{noToggle}, filters...)
+	}
+	instructions := h.ShortHelpView(bindings)
+	var hits []toggleHit
+	if m.filtering && m.view == viewPRs {
+		prompt := "Filter (label:name or text, enter to apply, esc to clear): "
+		instructions = prompt + m.filter.render(max(10, h.Width-len(prompt)), true)
+	} else if m.status.err != nil {
+		instructions = errorStyle.Render(truncate(m.status.err.Error(), h.Width))
+	} else if m.status.text != "" {
+		instructions = truncate(m.status.text, h.Width)
+	} else {
+		spans := shortHelpSpans(h, bindings)
+		for i, f := range filters {
+			if f != noToggle && spans[i].w > 0 {
+				hits = append(hits, toggleHit{area: spans[i], filter: f})
+			}
+		}
+	}
+	box := menuBox.Render(instructions)
+	x := menuBox.GetBorderLeftSize() + menuBox.GetPaddingLeft()
 	if m.width > 0 {
-		instructions = lipgloss.PlaceHorizontal(m.width, lipgloss.Center, menuBox.Render(instructions))
+		x += centerOffset(m.width, lipgloss.Width(box))
+		box = lipgloss.PlaceHorizontal(m.width, lipgloss.Center, box)
+	}
+	for i := range hits {
+		hits[i] = hits[i].at(x, menuBox.GetBorderTopSize())
 	}
-	return mainArea + "\n" + instructions
+	return box, hits
+}
+
+// renderHelp renders the full help overlay listing every binding
+func (m tuiModel) renderHelp(height int) string {
+	overlay := boxStyle.Render("Keys\n\n" + m.help.FullHelpView(m.keys.FullHelp()))
+	return lipgloss.Place(m.width, height, lipgloss.Center, lipgloss.Center, overlay)
 }
 
 // RunTUIWithError displays an error message in the TUI and exits on key press
@@ -299,24 +833,48 @@ func (m errorTUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m errorTUIModel) View() string {
-	return lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Bold(true).Render(
+	return errorStyle.Render(
 		"Error: " + m.errorMsg + "\nPress any key to exit.")
 }
 
-func initialModel(prs []PullRequestInfo, userID string) tuiModel {
+func initialModel(prs []PullRequestInfo, userID string, keys keyMap, session *Session) tuiModel {
 	return tuiModel{
 		prs:             prs,
 		selected:        0,
 		showDrafts:      false,
 		showMine:        false,
 		showNotReviewer: false,
+		checks:          make(map[int]prChecks),
+		conflicts:       make(map[int]prConflicts),
+		workItems:       make(map[int]prWorkItems),
+		historyDays:     defaultHistoryDays,
+		activity:        make(map[int]prActivity),
+		seen:            newSeenStore(),
+		ages:            agePolicy{warn: hours(0, defaultWarnHours), stale: hours(0, defaultStaleHours)},
+		keys:            keys,
+		help:            help.New(),
+		session:         session,
 		userID:          userID,
 		width:           0,
 		height:          0,
 	}
 }
 
-func RunTUI(prs []PullRequestInfo, userID string) {
-	p := tea.NewProgram(initialModel(prs, userID))
+func RunTUI(prs []PullRequestInfo, userID string, keys keyMap, session *Session, historyDays int, ages agePolicy, seen seenStore, watch WatchConfig) {
+	model := initialModel(prs, userID, keys, session)
+	model.seen = seen
+	model = model.seedSeen()
+	if watch.TUI {
+		// The bell goes to stderr so it does not disturb the screen
+		ns, err := newNotifiers(watch.Notifiers, watch.Command, os.Stderr)
+		if err != nil {
+			model.status = statusMsg{err: err}
+		} else {
+			model.watch, model.watchInterval, model.notifiers = newWatcher(session), watch.interval(), ns
+		}
+	}
+	model.historyDays = historyDays
+	model.ages = ages
+	p := tea.NewProgram(model, tea.WithMouseCellMotion())
 	_ = p.Start()
 }
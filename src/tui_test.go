@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// testModel returns a sized model listing prs for the user "me"
+func testModel(prs ...PullRequestInfo) tuiModel {
+	m := initialModel(prs, "me", defaultKeyMap(), nil)
+	m.width, m.height = 120, 30
+	return m
+}
+
+// reviewedByMe returns an open PR with "me" as a reviewer, so the default filters show it
+func reviewedByMe(id int, title string) PullRequestInfo {
+	return PullRequestInfo{id: id, title: title, creatorID: "ada", status: "active",
+		reviewers: []PullrequestReviewer{{id: "me", displayName: "Me"}}}
+}
+
+// update feeds a message to the model and renders it, failing the test on a panic
+func update(t *testing.T, m tuiModel, msg tea.Msg) tuiModel {
+	t.Helper()
+	model, _ := m.Update(msg)
+	m = model.(tuiModel)
+	m.View()
+	return m
+}
+
+func TestDraftingLastVisiblePRKeepsSelectionInRange(t *testing.T) {
+	m := testModel(reviewedByMe(1, "First"), reviewedByMe(2, "Second"))
+	m.selected = 1
+	drafted := m.prs[1]
+	drafted.IsDraft = true
+	m.form = formState{kind: formDraft, pr: m.prs[1], busy: true}
+	m.view = viewForm
+	m = update(t, m, formDoneMsg{kind: formDraft, pr: drafted})
+	if m.selected != 0 {
+		t.Fatalf("selected = %d, want 0 after the drafted PR was hidden", m.selected)
+	}
+}
+
+func TestRemovingMyselfAsReviewerKeepsSelectionInRange(t *testing.T) {
+	m := testModel(reviewedByMe(1, "First"), reviewedByMe(2, "Second"))
+	m.selected = 1
+	m = update(t, m, reviewersUpdatedMsg{prID: 2, reviewers: nil, text: "Removed Me"})
+	if m.selected != 0 {
+		t.Fatalf("selected = %d, want 0 after !2 left the list", m.selected)
+	}
+}
+
+func TestRemovingFilteredLabelKeepsSelectionInRange(t *testing.T) {
+	first, second := reviewedByMe(1, "First"), reviewedByMe(2, "Second")
+	first.labels, second.labels = []string{"bug"}, []string{"bug"}
+	m := testModel(first, second)
+	m.filter = textField("", "label:bug")
+	m.selected = 1
+	unlabeled := second
+	unlabeled.labels = nil
+	m.form = formState{kind: formLabels, pr: second, busy: true}
+	m.view = viewForm
+	m = update(t, m, formDoneMsg{kind: formLabels, pr: unlabeled})
+	if m.selected != 0 {
+		t.Fatalf("selected = %d, want 0 after !2 no longer matched the filter", m.selected)
+	}
+}
+
+func TestClosingPRKeepsItInModesListingItsStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		mode   prStatusMode
+		kind   formKind
+		status string
+		kept   bool
+	}{
+		{name: "complete in active", mode: statusActive, kind: formComplete, status: "completed", kept: false},
+		{name: "abandon in active", mode: statusActive, kind: formAbandon, status: "abandoned", kept: false},
+		{name: "complete in all", mode: statusAll, kind: formComplete, status: "completed", kept: true},
+		{name: "abandon in all", mode: statusAll, kind: formAbandon, status: "abandoned", kept: true},
+		{name: "complete in completed", mode: statusCompleted, kind: formComplete, status: "completed", kept: true},
+		{name: "reactivate in abandoned", mode: statusAbandoned, kind: formReactivate, status: "active", kept: false},
+		{name: "reactivate in all", mode: statusAll, kind: formReactivate, status: "active", kept: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testModel(reviewedByMe(1, "First"), reviewedByMe(2, "Second"))
+			m.statusMode = tt.mode
+			m.selected = 1
+			closed := m.prs[1]
+			closed.status = tt.status
+			m.form = formState{kind: tt.kind, pr: m.prs[1], busy: true}
+			m.view = viewForm
+			m = update(t, m, formDoneMsg{kind: tt.kind, pr: closed})
+			kept := false
+			for _, pr := range m.prs {
+				if pr.id == 2 {
+					kept = true
+					if pr.status != tt.status {
+						t.Errorf("status = %q, want %q", pr.status, tt.status)
+					}
+				}
+			}
+			if kept != tt.kept {
+				t.Fatalf("kept = %v, want %v", kept, tt.kept)
+			}
+		})
+	}
+}
+
+func TestFooterMarksTogglesWithoutColor(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	defer lipgloss.SetColorProfile(profile)
+
+	m := testModel(reviewedByMe(1, "First"))
+	m.showDrafts = true
+	lipgloss.SetColorProfile(termenv.Ascii)
+	footer, _ := m.renderFooter(layoutWide)
+	for _, want := range []string{"[x] d", "[ ] m"} {
+		if !strings.Contains(footer, want) {
+			t.Errorf("footer without color is missing %q:\n%s", want, footer)
+		}
+	}
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	if footer, _ := m.renderFooter(layoutWide); strings.Contains(footer, "[x]") {
+		t.Errorf("footer with color has markers:\n%s", footer)
+	}
+}
+
+func TestStaleFileLoadsAreDropped(t *testing.T) {
+	m := testModel(reviewedByMe(1, "First"))
+	m, _ = m.openFiles()
+	stale := m.files.seq
+	m.view = viewPRs
+	m, _ = m.openChangesSinceVote(m.prs[0])
+
+	m = update(t, m, filesLoadedMsg{seq: stale, iteration: 1, changes: []fileChange{{path: "stale.go"}}})
+	if !m.files.loading || len(m.files.changes) != 0 {
+		t.Fatalf("files = %+v, want the stale load dropped", m.files.changes)
+	}
+	m = update(t, m, filesLoadedMsg{seq: m.files.seq, iteration: 3, compareTo: 2, changes: []fileChange{{path: "a.go"}, {path: "b.go"}}})
+	if m.files.loading || m.files.iteration != 3 || len(m.files.changes) != 2 {
+		t.Fatalf("files = %+v, want the since-vote load", m.files)
+	}
+
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	first := m.diff.seq
+	m.view = viewFiles
+	m.files.selected = 1
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	m = update(t, m, diffLoadedMsg{seq: first, diff: fileDiff{binary: true}})
+	if !m.diff.loading || m.diff.diff.binary {
+		t.Fatalf("diff of %s was filled by the load of a.go", m.diff.change.path)
+	}
+	m = update(t, m, diffLoadedMsg{seq: m.diff.seq, diff: fileDiff{change: m.diff.change}})
+	if m.diff.loading {
+		t.Fatal("diff still loading after its own load")
+	}
+}
+
+func TestActivityInInboxKeepsSelectionOnPR(t *testing.T) {
+	m := testModel(reviewedByMe(1, "First"), reviewedByMe(2, "Second"), reviewedByMe(3, "Third"))
+	m.showInbox = true
+	for id := 1; id <= 3; id++ {
+		m.activity[id] = prActivity{replies: 1, last: time.Date(2026, 10, id, 0, 0, 0, 0, time.UTC)}
+	}
+	m.selected = 2
+	if pr, _ := m.selectedPR(); pr.id != 3 {
+		t.Fatalf("selected !%d, want !3", pr.id)
+	}
+
+	// Replies read elsewhere take !1 out of the inbox
+	m = update(t, m, activityLoadedMsg{prID: 1, activity: prActivity{}})
+	if pr, ok := m.selectedPR(); !ok || pr.id != 3 {
+		t.Fatalf("selected !%d, want the cursor to stay on !3", pr.id)
+	}
+	// And !3 leaving puts the cursor on the last remaining row
+	m = update(t, m, activityLoadedMsg{prID: 3, activity: prActivity{}})
+	if pr, ok := m.selectedPR(); !ok || pr.id != 2 {
+		t.Fatalf("selected !%d, want !2", pr.id)
+	}
+}
+
+func TestStaleIterationLoadsAreDropped(t *testing.T) {
+	m := testModel(reviewedByMe(1, "First"), reviewedByMe(2, "Second"))
+	m, _ = m.openIterations()
+	stale := m.iterations.seq
+	m.view = viewPRs
+	m.selected = 1
+	m, _ = m.openIterations()
+	m.view = viewPRs
+	m.selected = 0
+	m, _ = m.openIterations()
+
+	m = update(t, m, iterationsLoadedMsg{seq: stale, items: []iterationInfo{{id: 1}, {id: 2}}})
+	if !m.iterations.loading || len(m.iterations.items) != 0 {
+		t.Fatalf("iterations = %+v, want the load of the view left earlier dropped", m.iterations.items)
+	}
+	m = update(t, m, iterationsLoadedMsg{seq: m.iterations.seq, items: []iterationInfo{{id: 1}}})
+	if m.iterations.loading || len(m.iterations.items) != 1 {
+		t.Fatalf("iterations = %+v, want the latest load", m.iterations.items)
+	}
+}
+
+func TestReviewerListScrollsAndHidesIgnored(t *testing.T) {
+	pr := reviewedByMe(1, "Many reviewers")
+	pr.reviewers = append(pr.reviewers, PullrequestReviewer{id: "1809cf47-1683-62b4-ab66-9dbfd3d291d6", displayName: "Build Service"})
+	for i := range 40 {
+		pr.reviewers = append(pr.reviewers, PullrequestReviewer{id: fmt.Sprint("r", i), displayName: fmt.Sprintf("Reviewer %02d", i)})
+	}
+	model, _ := testModel(pr).openReviewers()
+	m := model.(tuiModel)
+	for range 40 {
+		m = update(t, m, tea.KeyMsg{Type: tea.KeyDown})
+	}
+	view := m.View()
+	if !strings.Contains(view, "Reviewer 39") {
+		t.Fatalf("the selected reviewer is off screen:\n%s", view)
+	}
+	if strings.Contains(view, "Build Service") {
+		t.Fatalf("an ignored reviewer is listed:\n%s", view)
+	}
+	if m.reviewers.selected != 40 {
+		t.Fatalf("selected = %d, want the last of 41 listed reviewers", m.reviewers.selected)
+	}
+}
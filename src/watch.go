@@ -0,0 +1,353 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"ml-winum.dk/azure-devops-tui/src/prdiff"
+)
+
+// watchEventKind is a kind of change found between two fetches of the open PRs
+type watchEventKind int
+
+const (
+	eventPRCreated watchEventKind = iota
+	eventPRPublished
+	eventReviewerAdded
+	eventVoteChanged
+	eventNewIteration
+	eventPRCompleted
+	eventPRAbandoned
+)
+
+var watchEventNames = []string{"pr_created", "pr_published", "reviewer_added", "vote_changed", "new_iteration", "pr_completed", "pr_abandoned"}
+
+func (k watchEventKind) String() string {
+	return watchEventNames[k]
+}
+
+// parseWatchEvent reads an event kind by name
+func parseWatchEvent(name string) (watchEventKind, error) {
+	for i, n := range watchEventNames {
+		if n == name {
+			return watchEventKind(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown event %q (available: %s)", name, strings.Join(watchEventNames, ", "))
+}
+
+// watchEvent is one change to a pull request
+type watchEvent struct {
+	kind       watchEventKind
+	pr         PullRequestInfo
+	before     PullRequestInfo // the PR in the previous fetch, unset for new PRs
+	reviewer   string          // who was added or voted
+	reviewerID string
+	vote       int
+	// personal events are about the user's own reviews and PRs, see forUser
+	personal bool
+}
+
+// summary is the headline of the event
+func (e watchEvent) summary() string {
+	switch e.kind {
+	case eventPRCreated, eventPRPublished, eventReviewerAdded:
+		if e.personal {
+			return fmt.Sprintf("Review requested on !%d", e.pr.id)
+		}
+		if e.kind == eventPRCreated {
+			return fmt.Sprintf("New PR !%d by %s", e.pr.id, e.pr.creator)
+		}
+		if e.kind == eventPRPublished {
+			return fmt.Sprintf("!%d is ready for review", e.pr.id)
+		}
+		return fmt.Sprintf("%s was added as a reviewer on !%d", e.reviewer, e.pr.id)
+	case eventVoteChanged:
+		return fmt.Sprintf("%s voted %s on !%d", e.reviewer, voteLabel(e.vote), e.pr.id)
+	case eventNewIteration:
+		return fmt.Sprintf("New changes pushed to !%d", e.pr.id)
+	}
+	verb := "completed"
+	if e.kind == eventPRAbandoned {
+		verb = "abandoned"
+	}
+	if e.pr.closedBy != "" {
+		return fmt.Sprintf("!%d was %s by %s", e.pr.id, verb, e.pr.closedBy)
+	}
+	return fmt.Sprintf("!%d was %s", e.pr.id, verb)
+}
+
+// body describes the pull request below the summary
+func (e watchEvent) body() string {
+	return fmt.Sprintf("%s (by %s)", e.pr.title, e.pr.creator)
+}
+
+// diffWatch compares two fetches of the open pull requests. PRs missing from
+// next are looked up in closed to tell completed from abandoned ones; drafts
+// are left out until they are published.
+func diffWatch(prev, next []PullRequestInfo, closed map[int]PullRequestInfo) []watchEvent {
+	before, after := prsByID(prev), prsByID(next)
+	var events []watchEvent
+	for _, change := range prdiff.Diff(diffSnapshot(prev), diffSnapshot(next)) {
+		id := change.PR().ID
+		pr, old := after[id], before[id]
+		switch {
+		case change.Kind == prdiff.Removed:
+			if old.IsDraft {
+				continue
+			}
+		case pr.IsDraft, old.IsDraft && change.Kind != prdiff.Published:
+			continue
+		}
+		event := watchEvent{pr: pr, before: old, reviewer: change.Reviewer.Name, reviewerID: change.Reviewer.ID, vote: change.Reviewer.Vote}
+		switch change.Kind {
+		case prdiff.Added:
+			event.kind = eventPRCreated
+		case prdiff.Published:
+			event.kind = eventPRPublished
+		case prdiff.ReviewerAdded:
+			event.kind, event.vote = eventReviewerAdded, 0
+			events = append(events, event)
+			// A reviewer may vote before the next fetch
+			if change.Reviewer.Vote == 0 {
+				continue
+			}
+			event.kind, event.vote = eventVoteChanged, change.Reviewer.Vote
+		case prdiff.VoteChanged:
+			event.kind = eventVoteChanged
+		case prdiff.Pushed:
+			if old.lastMergeSourceCommit == "" {
+				continue
+			}
+			event.kind = eventNewIteration
+		case prdiff.Removed:
+			info, ok := closed[id]
+			switch {
+			case !ok:
+				continue
+			case info.status == "completed":
+				event.kind = eventPRCompleted
+			case info.status == "abandoned":
+				event.kind = eventPRAbandoned
+			default:
+				continue
+			}
+			event.pr = info
+		default:
+			continue
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// prsByID indexes pull requests by id
+func prsByID(prs []PullRequestInfo) map[int]PullRequestInfo {
+	byID := make(map[int]PullRequestInfo, len(prs))
+	for _, pr := range prs {
+		byID[pr.id] = pr
+	}
+	return byID
+}
+
+// forUser keeps the events that concern userID, marked as personal: being
+// asked to review, votes on their own PRs and new pushes to PRs they voted on
+func forUser(events []watchEvent, userID string) []watchEvent {
+	var personal []watchEvent
+	for _, e := range events {
+		mine := e.pr.creatorID == userID
+		keep := false
+		switch e.kind {
+		case eventPRCreated, eventPRPublished:
+			keep = !mine && findReviewer(e.pr, userID) != nil
+		case eventReviewerAdded:
+			keep = !mine && e.reviewerID == userID
+		case eventVoteChanged:
+			keep = mine && e.reviewerID != userID
+		case eventNewIteration:
+			keep = !mine && reviewerVote(findReviewer(e.before, userID)) != 0
+		}
+		if keep {
+			e.personal = true
+			personal = append(personal, e)
+		}
+	}
+	return personal
+}
+
+// findReviewer returns the reviewer with the id, or nil when there is none
+func findReviewer(pr PullRequestInfo, id string) *PullrequestReviewer {
+	for i := range pr.reviewers {
+		if pr.reviewers[i].id == id {
+			return &pr.reviewers[i]
+		}
+	}
+	return nil
+}
+
+// reviewerVote returns the vote of a reviewer, no vote when there is none
+func reviewerVote(rev *PullrequestReviewer) int {
+	if rev == nil {
+		return 0
+	}
+	return rev.vote
+}
+
+// watcher fetches the open pull requests and reports what changed since the
+// previous fetch. The first fetch only records where to start from.
+type watcher struct {
+	list func() ([]PullRequestInfo, error)
+	// lookup fetches a PR that is no longer open, to learn how it was closed
+	lookup func(id int) (PullRequestInfo, error)
+	prev   []PullRequestInfo
+	primed bool
+}
+
+func newWatcher(session *Session) *watcher {
+	return &watcher{
+		list:   func() ([]PullRequestInfo, error) { return session.ListPullRequests(statusActive, time.Time{}) },
+		lookup: session.GetPullRequest,
+	}
+}
+
+// poll fetches the pull requests and returns the events since the last poll
+func (w *watcher) poll() ([]watchEvent, error) {
+	prs, err := w.list()
+	if err != nil {
+		return nil, err
+	}
+	var events []watchEvent
+	if w.primed {
+		open := make(map[int]bool, len(prs))
+		for _, pr := range prs {
+			open[pr.id] = true
+		}
+		closed := make(map[int]PullRequestInfo)
+		for _, pr := range w.prev {
+			if open[pr.id] || pr.IsDraft || w.lookup == nil {
+				continue
+			}
+			// A PR that cannot be looked up, e.g. in a deleted repository, goes unreported
+			if info, err := w.lookup(pr.id); err == nil {
+				closed[pr.id] = info
+			}
+		}
+		events = diffWatch(w.prev, prs, closed)
+	}
+	w.prev, w.primed = prs, true
+	return events, nil
+}
+
+// RunWatch implements `AzurePR watch`, polling the open pull requests and
+// notifying about changes until interrupted
+func RunWatch(session *Session, userID string, args []string, config WatchConfig) error {
+	flags := flag.NewFlagSet("watch", flag.ContinueOnError)
+	interval := flags.Duration("interval", config.interval(), "how often to check for changes")
+	names := flags.String("notify", strings.Join(config.Notifiers, ","), "comma separated notifiers: desktop, bell, command (default: desktop and bell)")
+	command := flags.String("command", config.Command, "shell command run for every event by the command notifier")
+	dryRun := flags.Bool("dry-run", false, "print webhook requests instead of sending them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	ns, err := newNotifiers(splitList(*names), *command, os.Stdout)
+	if err != nil {
+		return err
+	}
+	var hookOut io.Writer
+	if *dryRun {
+		hookOut = os.Stdout
+	}
+	hooks, err := newWebhookNotifiers(config.Webhooks, hookOut)
+	if err != nil {
+		return err
+	}
+	if *dryRun && len(hooks) == 0 {
+		fmt.Println("No webhooks are configured, so -dry-run has no requests to print. Add them under watch.webhooks in the config.")
+	}
+	queue := startWebhooks(hooks, os.Stdout)
+	defer queue.stop()
+	w := newWatcher(session)
+	// Failing to list the PRs at all is fatal, later failures are retried
+	if _, err := w.poll(); err != nil {
+		return err
+	}
+	fmt.Printf("Watching %d open pull requests, checking every %s. Press Ctrl+C to stop.\n", len(w.prev), *interval)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// A second Ctrl+C quits without waiting
+			stop()
+			fmt.Println("Stopping once the queued webhooks are sent.")
+			return nil
+		case <-ticker.C:
+		}
+		events, err := w.poll()
+		if err != nil {
+			fmt.Println("Error checking pull requests:", err)
+			continue
+		}
+		personal := forUser(events, userID)
+		for _, event := range personal {
+			fmt.Printf("%s %s: %s\n", time.Now().Format("15:04"), event.summary(), event.body())
+		}
+		if err := ns.dispatch(personal); err != nil {
+			fmt.Println("Error sending notifications:", err)
+		}
+		if !queue.send(events) {
+			fmt.Printf("Dropped %d webhook events, the earlier ones are still being sent\n", len(events))
+		}
+	}
+}
+
+// watchTickMsg asks the TUI to check for changes
+type watchTickMsg struct{}
+
+// watchPolledMsg carries the events found while the TUI runs
+type watchPolledMsg struct {
+	events []watchEvent
+	err    error
+}
+
+// watchTickCmd schedules the next check, or nothing when the TUI does not watch
+func (m tuiModel) watchTickCmd() tea.Cmd {
+	if m.watch == nil {
+		return nil
+	}
+	return tea.Tick(m.watchInterval, func(time.Time) tea.Msg { return watchTickMsg{} })
+}
+
+// pollWatchCmd checks for changes and notifies about them in the background
+func (m tuiModel) pollWatchCmd() tea.Cmd {
+	w, ns, userID := m.watch, m.notifiers, m.userID
+	return func() tea.Msg {
+		events, err := w.poll()
+		events = forUser(events, userID)
+		if err == nil {
+			err = ns.dispatch(events)
+		}
+		return watchPolledMsg{events: events, err: err}
+	}
+}
+
+// watchPolled shows the latest event in the footer and schedules the next check
+func (m tuiModel) watchPolled(msg watchPolledMsg) (tuiModel, tea.Cmd) {
+	switch {
+	case msg.err != nil:
+		m.status = statusMsg{err: msg.err}
+	case len(msg.events) > 0:
+		m.status = statusMsg{text: "🔔 " + msg.events[len(msg.events)-1].summary()}
+	}
+	return m, m.watchTickCmd()
+}
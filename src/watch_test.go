@@ -0,0 +1,211 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+const me = "me"
+
+func reviewers(revs ...PullrequestReviewer) []PullrequestReviewer {
+	return revs
+}
+
+// eventKeys describes events compactly for comparison
+func eventKeys(events []watchEvent) []string {
+	var keys []string
+	for _, e := range events {
+		key := fmt.Sprintf("%s !%d", e.kind, e.pr.id)
+		if e.reviewerID != "" {
+			key += fmt.Sprintf(" %s=%d", e.reviewerID, e.vote)
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func TestDiffWatch(t *testing.T) {
+	pr := PullRequestInfo{id: 1, title: "Fix", creatorID: "ada", lastMergeSourceCommit: "a1",
+		reviewers: reviewers(PullrequestReviewer{id: "bob", displayName: "Bob"})}
+	draft := pr
+	draft.IsDraft = true
+	approved := pr
+	approved.reviewers = reviewers(PullrequestReviewer{id: "bob", displayName: "Bob", vote: 10})
+	withEve := pr
+	withEve.reviewers = reviewers(PullrequestReviewer{id: "bob", displayName: "Bob"}, PullrequestReviewer{id: "eve", displayName: "Eve", vote: -5})
+	pushed := pr
+	pushed.lastMergeSourceCommit = "b2"
+	completed := pr
+	completed.status = "completed"
+	abandoned := pr
+	abandoned.status = "abandoned"
+
+	tests := []struct {
+		name       string
+		prev, next []PullRequestInfo
+		closed     map[int]PullRequestInfo
+		want       []string
+	}{
+		{name: "nothing changed", prev: []PullRequestInfo{pr}, next: []PullRequestInfo{pr}},
+		{name: "new pr", next: []PullRequestInfo{pr}, want: []string{"pr_created !1"}},
+		{name: "new draft", next: []PullRequestInfo{draft}},
+		{name: "draft published", prev: []PullRequestInfo{draft}, next: []PullRequestInfo{pr}, want: []string{"pr_published !1"}},
+		{name: "changes to a draft", prev: []PullRequestInfo{draft}, next: []PullRequestInfo{draft}},
+		{name: "vote", prev: []PullRequestInfo{pr}, next: []PullRequestInfo{approved}, want: []string{"vote_changed !1 bob=10"}},
+		{name: "vote reset", prev: []PullRequestInfo{approved}, next: []PullRequestInfo{pr}, want: []string{"vote_changed !1 bob=0"}},
+		{name: "reviewer added with a vote", prev: []PullRequestInfo{pr}, next: []PullRequestInfo{withEve},
+			want: []string{"reviewer_added !1 eve=0", "vote_changed !1 eve=-5"}},
+		{name: "push", prev: []PullRequestInfo{pr}, next: []PullRequestInfo{pushed}, want: []string{"new_iteration !1"}},
+		{name: "completed", prev: []PullRequestInfo{pr}, closed: map[int]PullRequestInfo{1: completed}, want: []string{"pr_completed !1"}},
+		{name: "abandoned", prev: []PullRequestInfo{pr}, closed: map[int]PullRequestInfo{1: abandoned}, want: []string{"pr_abandoned !1"}},
+		{name: "gone without lookup", prev: []PullRequestInfo{pr}},
+		{name: "draft abandoned", prev: []PullRequestInfo{draft}, closed: map[int]PullRequestInfo{1: abandoned}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := eventKeys(diffWatch(tt.prev, tt.next, tt.closed))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("diffWatch() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForUser(t *testing.T) {
+	reviewedByMe := PullRequestInfo{id: 1, creatorID: "ada", lastMergeSourceCommit: "a1",
+		reviewers: reviewers(PullrequestReviewer{id: me, vote: 10})}
+	pushed := reviewedByMe
+	pushed.lastMergeSourceCommit = "b2"
+	pushed.reviewers = reviewers(PullrequestReviewer{id: me}) // the push reset my vote
+
+	notVoted := reviewedByMe
+	notVoted.reviewers = reviewers(PullrequestReviewer{id: me})
+	notVotedPushed := notVoted
+	notVotedPushed.lastMergeSourceCommit = "b2"
+
+	mine := PullRequestInfo{id: 2, creatorID: me, reviewers: reviewers(PullrequestReviewer{id: "bob"})}
+	mineApproved := mine
+	mineApproved.reviewers = reviewers(PullrequestReviewer{id: "bob", vote: 10})
+	mineSelfVote := mine
+	mineSelfVote.reviewers = reviewers(PullrequestReviewer{id: "bob"}, PullrequestReviewer{id: me, vote: 10})
+
+	other := PullRequestInfo{id: 3, creatorID: "ada", reviewers: reviewers(PullrequestReviewer{id: "bob"})}
+	askedMe := other
+	askedMe.reviewers = reviewers(PullrequestReviewer{id: "bob"}, PullrequestReviewer{id: me})
+	draftAskingMe := askedMe
+	draftAskingMe.IsDraft = true
+	othersApproved := other
+	othersApproved.reviewers = reviewers(PullrequestReviewer{id: "bob", vote: 10})
+
+	tests := []struct {
+		name       string
+		prev, next []PullRequestInfo
+		want       []string
+	}{
+		{name: "added as reviewer", prev: []PullRequestInfo{other}, next: []PullRequestInfo{askedMe}, want: []string{"reviewer_added !3 me=0"}},
+		{name: "new pr asking me", next: []PullRequestInfo{askedMe}, want: []string{"pr_created !3"}},
+		{name: "new draft asking me", next: []PullRequestInfo{draftAskingMe}},
+		{name: "draft asking me published", prev: []PullRequestInfo{draftAskingMe}, next: []PullRequestInfo{askedMe}, want: []string{"pr_published !3"}},
+		{name: "new pr not asking me", next: []PullRequestInfo{other}},
+		{name: "own new pr listing me", next: []PullRequestInfo{mineSelfVote}},
+		{name: "vote on my pr", prev: []PullRequestInfo{mine}, next: []PullRequestInfo{mineApproved}, want: []string{"vote_changed !2 bob=10"}},
+		{name: "my own vote on my pr", prev: []PullRequestInfo{mine}, next: []PullRequestInfo{mineSelfVote}},
+		{name: "vote on someone else's pr", prev: []PullRequestInfo{other}, next: []PullRequestInfo{othersApproved}},
+		{name: "push after my vote", prev: []PullRequestInfo{reviewedByMe}, next: []PullRequestInfo{pushed}, want: []string{"new_iteration !1"}},
+		{name: "push before my vote", prev: []PullRequestInfo{notVoted}, next: []PullRequestInfo{notVotedPushed}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := forUser(diffWatch(tt.prev, tt.next, nil), me)
+			if got := eventKeys(events); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("forUser() = %q, want %q", got, tt.want)
+			}
+			for _, e := range events {
+				if !e.personal {
+					t.Errorf("%s is not marked personal", e.kind)
+				}
+			}
+		})
+	}
+}
+
+func TestPersonalSummary(t *testing.T) {
+	e := watchEvent{kind: eventReviewerAdded, pr: PullRequestInfo{id: 7}, reviewer: "Me"}
+	if got := e.summary(); got != "Me was added as a reviewer on !7" {
+		t.Errorf("summary() = %q", got)
+	}
+	e.personal = true
+	if got := e.summary(); got != "Review requested on !7" {
+		t.Errorf("personal summary() = %q", got)
+	}
+}
+
+func TestWatcherPrimesOnFirstPoll(t *testing.T) {
+	other := PullRequestInfo{id: 3, title: "Other", creatorID: "ada"}
+	askedMe := other
+	askedMe.reviewers = reviewers(PullrequestReviewer{id: me, displayName: "Me"})
+	fetches := [][]PullRequestInfo{{other}, {other}, {askedMe}}
+	failNext := false
+	w := &watcher{list: func() ([]PullRequestInfo, error) {
+		if failNext {
+			failNext = false
+			return nil, errors.New("offline")
+		}
+		prs := fetches[0]
+		fetches = fetches[1:]
+		return prs, nil
+	}}
+	for i := 0; i < 2; i++ {
+		events, err := w.poll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) != 0 {
+			t.Fatalf("poll %d returned %v, want no events", i, eventKeys(events))
+		}
+	}
+	// A failed poll keeps the previous snapshot
+	failNext = true
+	if _, err := w.poll(); err == nil {
+		t.Fatal("expected the fetch error")
+	}
+	events, err := w.poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := eventKeys(events); !reflect.DeepEqual(got, []string{"reviewer_added !3 me=0"}) {
+		t.Fatalf("events = %q, want the review request", got)
+	}
+}
+
+func TestWatcherLooksUpClosedPRs(t *testing.T) {
+	open := PullRequestInfo{id: 1, status: "active"}
+	fetches := [][]PullRequestInfo{{open}, nil}
+	var looked []int
+	w := &watcher{
+		list: func() ([]PullRequestInfo, error) {
+			prs := fetches[0]
+			fetches = fetches[1:]
+			return prs, nil
+		},
+		lookup: func(id int) (PullRequestInfo, error) {
+			looked = append(looked, id)
+			return PullRequestInfo{id: id, status: "completed", closedBy: "Ada"}, nil
+		},
+	}
+	if _, err := w.poll(); err != nil {
+		t.Fatal(err)
+	}
+	events, err := w.poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(looked, []int{1}) {
+		t.Fatalf("looked up %v, want [1]", looked)
+	}
+	if len(events) != 1 || events[0].summary() != "!1 was completed by Ada" {
+		t.Fatalf("events = %q, want the completion", eventKeys(events))
+	}
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// webhookPresets are the payload templates of the supported chat services
+var webhookPresets = map[string]string{
+	"slack": `{"text": {{json (printf "%s: <%s|%s>" .Summary .PR.URL .PR.Title)}}}`,
+	"teams": `{
+  "@type": "MessageCard",
+  "@context": "https://schema.org/extensions",
+  "summary": {{json .Summary}},
+  "title": {{json .Summary}},
+  "text": {{json .Body}},
+  "potentialAction": [{"@type": "OpenUri", "name": "Open PR", "targets": [{"os": "default", "uri": {{json .PR.URL}}}]}]
+}`,
+}
+
+// webhookPayload is the plain JSON body of an event, and the data templates render
+type webhookPayload struct {
+	Event    string    `json:"event"`
+	Summary  string    `json:"summary"`
+	Body     string    `json:"body"`
+	PR       webhookPR `json:"pr"`
+	Reviewer string    `json:"reviewer,omitempty"`
+	Vote     string    `json:"vote,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+type webhookPR struct {
+	ID           int    `json:"id"`
+	Title        string `json:"title"`
+	URL          string `json:"url"`
+	Repository   string `json:"repository"`
+	Author       string `json:"author"`
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	Draft        bool   `json:"draft"`
+}
+
+func newWebhookPayload(event watchEvent, now time.Time) webhookPayload {
+	payload := webhookPayload{
+		Event:    event.kind.String(),
+		Summary:  event.summary(),
+		Body:     event.body(),
+		Reviewer: event.reviewer,
+		Time:     now.UTC(),
+		PR: webhookPR{
+			ID:           event.pr.id,
+			Title:        event.pr.title,
+			URL:          event.pr.url,
+			Repository:   event.pr.repository,
+			Author:       event.pr.creator,
+			SourceBranch: event.pr.sourceBranch,
+			TargetBranch: event.pr.targetBranch,
+			Draft:        event.pr.IsDraft,
+		},
+	}
+	if event.kind == eventVoteChanged {
+		payload.Vote = voteLabel(event.vote)
+	}
+	return payload
+}
+
+// defaultWebhookRetries is how often a failed post is retried unless configured
+const defaultWebhookRetries = 3
+
+// webhookNotifier posts events to a URL
+type webhookNotifier struct {
+	url      string
+	template *template.Template // nil posts the plain payload
+	secret   string
+	events   map[watchEventKind]bool // nil posts every event
+	retries  int
+	backoff  time.Duration // wait before the first retry, doubled after each
+	client   *http.Client
+	// dryRun writes the requests here instead of sending them
+	dryRun io.Writer
+	now    func() time.Time
+}
+
+// newWebhookNotifier checks the webhook config and builds its notifier
+func newWebhookNotifier(config WebhookConfig, dryRun io.Writer) (*webhookNotifier, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("webhook without a url")
+	}
+	n := &webhookNotifier{
+		url:     config.URL,
+		secret:  config.Secret,
+		retries: defaultWebhookRetries,
+		backoff: time.Second,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		dryRun:  dryRun,
+		now:     time.Now,
+	}
+	if config.Retries != 0 {
+		n.retries = max(0, config.Retries)
+	}
+	text := config.Template
+	if text == "" && config.Preset != "" {
+		preset, ok := webhookPresets[config.Preset]
+		if !ok {
+			return nil, fmt.Errorf("webhook %s: unknown preset %q (available: slack, teams)", config.URL, config.Preset)
+		}
+		text = preset
+	}
+	if text != "" {
+		tmpl, err := template.New(config.URL).Funcs(template.FuncMap{"json": toJSON}).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("webhook %s: invalid template: %w", config.URL, err)
+		}
+		n.template = tmpl
+	}
+	for _, name := range config.Events {
+		kind, err := parseWatchEvent(name)
+		if err != nil {
+			return nil, fmt.Errorf("webhook %s: %w", config.URL, err)
+		}
+		if n.events == nil {
+			n.events = make(map[watchEventKind]bool)
+		}
+		n.events[kind] = true
+	}
+	return n, nil
+}
+
+// newWebhookNotifiers builds a notifier for every configured webhook
+func newWebhookNotifiers(configs []WebhookConfig, dryRun io.Writer) (notifiers, error) {
+	var ns notifiers
+	for _, config := range configs {
+		n, err := newWebhookNotifier(config, dryRun)
+		if err != nil {
+			return nil, err
+		}
+		ns = append(ns, n)
+	}
+	return ns, nil
+}
+
+// toJSON quotes a value for use inside a JSON template. Unlike json.Marshal it
+// keeps <, > and & as they are, as Slack links need them.
+func toJSON(v any) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// render builds the request body of an event
+func (n *webhookNotifier) render(event watchEvent) ([]byte, error) {
+	payload := newWebhookPayload(event, n.now())
+	if n.template == nil {
+		return json.Marshal(payload)
+	}
+	var buf bytes.Buffer
+	if err := n.template.Execute(&buf, payload); err != nil {
+		return nil, fmt.Errorf("webhook %s: %w", n.url, err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("webhook %s: template did not produce valid JSON", n.url)
+	}
+	return buf.Bytes(), nil
+}
+
+// sign returns the signature header value of a body
+func (n *webhookNotifier) sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(n.secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (n *webhookNotifier) notify(event watchEvent) error {
+	if n.events != nil && !n.events[event.kind] {
+		return nil
+	}
+	body, err := n.render(event)
+	if err != nil {
+		return err
+	}
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("X-AzurePR-Event", event.kind.String())
+	if n.secret != "" {
+		header.Set("X-AzurePR-Signature", n.sign(body))
+	}
+	if n.dryRun != nil {
+		fmt.Fprintf(n.dryRun, "POST %s\n", n.url)
+		for _, name := range []string{"Content-Type", "X-AzurePR-Event", "X-AzurePR-Signature"} {
+			if value := header.Get(name); value != "" {
+				fmt.Fprintf(n.dryRun, "%s: %s\n", name, value)
+			}
+		}
+		fmt.Fprintf(n.dryRun, "\n%s\n\n", body)
+		return nil
+	}
+	wait := n.backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := n.post(body, header)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= n.retries {
+			return fmt.Errorf("webhook %s: %w", n.url, err)
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// webhookQueueSize is how many checks worth of events may wait for delivery
+const webhookQueueSize = 16
+
+// webhookQueue posts webhooks in the background, so retries against a slow or
+// failing endpoint do not hold up the next check
+type webhookQueue struct {
+	pending chan []watchEvent
+	done    chan struct{}
+}
+
+// startWebhooks starts sending queued events, writing failures to errs
+func startWebhooks(hooks notifiers, errs io.Writer) *webhookQueue {
+	q := &webhookQueue{pending: make(chan []watchEvent, webhookQueueSize), done: make(chan struct{})}
+	go func() {
+		defer close(q.done)
+		// One sender keeps the events in order
+		for events := range q.pending {
+			if err := hooks.dispatch(events); err != nil {
+				fmt.Fprintln(errs, "Error sending webhooks:", err)
+			}
+		}
+	}()
+	return q
+}
+
+// send queues events, reporting false when the queue is full and they were dropped
+func (q *webhookQueue) send(events []watchEvent) bool {
+	if len(events) == 0 {
+		return true
+	}
+	select {
+	case q.pending <- events:
+		return true
+	default:
+		return false
+	}
+}
+
+// stop waits for the queued events to be sent
+func (q *webhookQueue) stop() {
+	close(q.pending)
+	<-q.done
+}
+
+// post sends one request, reporting whether a failure is worth retrying
+func (n *webhookNotifier) post(body []byte, header http.Header) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header = header.Clone()
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	reply, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(reply)))
+	// Server errors and rate limits may pass, other client errors will not
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests, err
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// hookServer is a local stand-in for a webhook receiver that answers with the
+// given statuses in turn and records every request
+type hookServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	bodies   [][]byte
+	headers  []http.Header
+}
+
+func newHookServer(t *testing.T, statuses ...int) *hookServer {
+	s := &hookServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.bodies = append(s.bodies, body)
+		s.headers = append(s.headers, r.Header.Clone())
+		status := http.StatusOK
+		if len(s.statuses) > 0 {
+			status, s.statuses = s.statuses[0], s.statuses[1:]
+		}
+		w.WriteHeader(status)
+		io.WriteString(w, http.StatusText(status))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *hookServer) requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.bodies)
+}
+
+func webhookEvent() watchEvent {
+	return watchEvent{
+		kind:       eventVoteChanged,
+		pr:         PullRequestInfo{id: 42, title: `Fix "login"`, creator: "Ada", url: "https://example.com/pr/42", repository: "web", sourceBranch: "fix", targetBranch: "main"},
+		reviewer:   "Bob",
+		reviewerID: "bob",
+		vote:       10,
+	}
+}
+
+func testWebhook(t *testing.T, config WebhookConfig) *webhookNotifier {
+	t.Helper()
+	n, err := newWebhookNotifier(config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.backoff = time.Millisecond
+	n.now = func() time.Time { return time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC) }
+	return n
+}
+
+func TestWebhookPostsPlainJSON(t *testing.T) {
+	server := newHookServer(t)
+	if err := testWebhook(t, WebhookConfig{URL: server.URL}).notify(webhookEvent()); err != nil {
+		t.Fatal(err)
+	}
+	if server.requests() != 1 {
+		t.Fatalf("got %d requests, want 1", server.requests())
+	}
+	var payload webhookPayload
+	if err := json.Unmarshal(server.bodies[0], &payload); err != nil {
+		t.Fatal(err)
+	}
+	want := webhookPayload{
+		Event:    "vote_changed",
+		Summary:  "Bob voted Approved on !42",
+		Body:     `Fix "login" (by Ada)`,
+		Reviewer: "Bob",
+		Vote:     "Approved",
+		Time:     time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC),
+		PR:       webhookPR{ID: 42, Title: `Fix "login"`, URL: "https://example.com/pr/42", Repository: "web", Author: "Ada", SourceBranch: "fix", TargetBranch: "main"},
+	}
+	if payload != want {
+		t.Fatalf("payload = %+v\nwant %+v", payload, want)
+	}
+	header := server.headers[0]
+	if header.Get("Content-Type") != "application/json" || header.Get("X-AzurePR-Event") != "vote_changed" {
+		t.Errorf("headers = %v", header)
+	}
+	if header.Get("X-AzurePR-Signature") != "" {
+		t.Error("unsigned webhook sent a signature")
+	}
+}
+
+func TestWebhookPresets(t *testing.T) {
+	tests := []struct {
+		preset string
+		check  func(t *testing.T, body map[string]any)
+	}{
+		{preset: "slack", check: func(t *testing.T, body map[string]any) {
+			if body["text"] != `Bob voted Approved on !42: <https://example.com/pr/42|Fix "login">` {
+				t.Errorf("text = %q", body["text"])
+			}
+		}},
+		{preset: "teams", check: func(t *testing.T, body map[string]any) {
+			if body["@type"] != "MessageCard" || body["title"] != "Bob voted Approved on !42" || body["text"] != `Fix "login" (by Ada)` {
+				t.Errorf("card = %v", body)
+			}
+			actions, _ := body["potentialAction"].([]any)
+			if len(actions) != 1 || !strings.Contains(string(mustJSON(t, actions[0])), "https://example.com/pr/42") {
+				t.Errorf("actions = %v", actions)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.preset, func(t *testing.T) {
+			server := newHookServer(t)
+			if err := testWebhook(t, WebhookConfig{URL: server.URL, Preset: tt.preset}).notify(webhookEvent()); err != nil {
+				t.Fatal(err)
+			}
+			var body map[string]any
+			if err := json.Unmarshal(server.bodies[0], &body); err != nil {
+				t.Fatalf("invalid JSON %s: %v", server.bodies[0], err)
+			}
+			tt.check(t, body)
+		})
+	}
+}
+
+func mustJSON(t *testing.T, v any) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestWebhookCustomTemplate(t *testing.T) {
+	server := newHookServer(t)
+	n := testWebhook(t, WebhookConfig{URL: server.URL, Template: `{"msg": {{json .Summary}}, "id": {{.PR.ID}}}`})
+	if err := n.notify(webhookEvent()); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(server.bodies[0]); got != `{"msg": "Bob voted Approved on !42", "id": 42}` {
+		t.Fatalf("body = %s", got)
+	}
+}
+
+func TestWebhookRejectsTemplateWithoutJSON(t *testing.T) {
+	server := newHookServer(t)
+	n := testWebhook(t, WebhookConfig{URL: server.URL, Template: `{{.Summary}}`})
+	if err := n.notify(webhookEvent()); err == nil || !strings.Contains(err.Error(), "valid JSON") {
+		t.Fatalf("err = %v, want invalid JSON", err)
+	}
+	if server.requests() != 0 {
+		t.Fatal("posted an invalid payload")
+	}
+}
+
+func TestWebhookSignsPayload(t *testing.T) {
+	server := newHookServer(t)
+	if err := testWebhook(t, WebhookConfig{URL: server.URL, Secret: "s3cret"}).notify(webhookEvent()); err != nil {
+		t.Fatal(err)
+	}
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(server.bodies[0])
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := server.headers[0].Get("X-AzurePR-Signature"); got != want {
+		t.Fatalf("signature = %q, want %q", got, want)
+	}
+}
+
+func TestWebhookRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		retries      int
+		wantRequests int
+		wantErr      string
+	}{
+		{name: "succeeds after server errors", statuses: []int{500, 503, 200}, wantRequests: 3},
+		{name: "retries rate limits", statuses: []int{429, 200}, wantRequests: 2},
+		{name: "gives up after retries", statuses: []int{500, 500, 500, 500, 500}, wantRequests: 4, wantErr: "status 500"},
+		{name: "configured retries", statuses: []int{500, 500, 200}, retries: 1, wantRequests: 2, wantErr: "status 500"},
+		{name: "never retries", statuses: []int{500, 200}, retries: -1, wantRequests: 1, wantErr: "status 500"},
+		{name: "client errors are final", statuses: []int{404, 200}, wantRequests: 1, wantErr: "status 404: Not Found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newHookServer(t, tt.statuses...)
+			err := testWebhook(t, WebhookConfig{URL: server.URL, Retries: tt.retries}).notify(webhookEvent())
+			if tt.wantErr == "" && err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+			if server.requests() != tt.wantRequests {
+				t.Fatalf("got %d requests, want %d", server.requests(), tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestWebhookRetriesUnreachableServer(t *testing.T) {
+	server := newHookServer(t)
+	url := server.URL
+	server.Close()
+	err := testWebhook(t, WebhookConfig{URL: url, Retries: 1}).notify(webhookEvent())
+	if err == nil || !strings.Contains(err.Error(), url) {
+		t.Fatalf("err = %v, want a connection error", err)
+	}
+}
+
+func TestWebhookFiltersEvents(t *testing.T) {
+	server := newHookServer(t)
+	n := testWebhook(t, WebhookConfig{URL: server.URL, Events: []string{"pr_created", "pr_completed"}})
+	created := webhookEvent()
+	created.kind = eventPRCreated
+	for _, event := range []watchEvent{webhookEvent(), created} {
+		if err := n.notify(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if server.requests() != 1 || server.headers[0].Get("X-AzurePR-Event") != "pr_created" {
+		t.Fatalf("got %d requests, want only pr_created", server.requests())
+	}
+}
+
+func TestWebhookDryRun(t *testing.T) {
+	server := newHookServer(t)
+	var out bytes.Buffer
+	n, err := newWebhookNotifier(WebhookConfig{URL: server.URL, Preset: "slack", Secret: "s3cret"}, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.notify(webhookEvent()); err != nil {
+		t.Fatal(err)
+	}
+	if server.requests() != 0 {
+		t.Fatal("dry run sent a request")
+	}
+	for _, want := range []string{"POST " + server.URL, "X-AzurePR-Event: vote_changed", "X-AzurePR-Signature: sha256=", `"text":`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("dry run output misses %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestNewWebhookNotifierValidates(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  WebhookConfig
+		wantErr string
+	}{
+		{name: "no url", config: WebhookConfig{Preset: "slack"}, wantErr: "without a url"},
+		{name: "unknown preset", config: WebhookConfig{URL: "http://x", Preset: "irc"}, wantErr: `unknown preset "irc"`},
+		{name: "broken template", config: WebhookConfig{URL: "http://x", Template: "{{.Summary"}, wantErr: "invalid template"},
+		{name: "unknown event", config: WebhookConfig{URL: "http://x", Events: []string{"pr_merged"}}, wantErr: `unknown event "pr_merged"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newWebhookNotifiers([]WebhookConfig{tt.config}, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebhookQueueDoesNotBlock(t *testing.T) {
+	arrived, release := make(chan struct{}, webhookQueueSize+2), make(chan struct{})
+	var mu sync.Mutex
+	var ids []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		<-release
+		var payload webhookPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		ids = append(ids, payload.PR.ID)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	var errs bytes.Buffer
+	queue := startWebhooks(notifiers{testWebhook(t, WebhookConfig{URL: server.URL})}, &errs)
+	event := func(id int) []watchEvent {
+		e := webhookEvent()
+		e.pr.id = id
+		return []watchEvent{e}
+	}
+	start := time.Now()
+	queue.send(event(1))
+	<-arrived
+	// The first batch is being sent while the queue fills up behind it
+	for id := 2; id <= webhookQueueSize+1; id++ {
+		if !queue.send(event(id)) {
+			t.Fatalf("batch %d was dropped", id)
+		}
+	}
+	if queue.send(event(0)) {
+		t.Fatal("a batch was queued past the queue size")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("queueing took %s", elapsed)
+	}
+	close(release)
+	queue.stop()
+	for i, id := range ids {
+		if id != i+1 {
+			t.Fatalf("sent %v, want every queued batch in order", ids)
+		}
+	}
+	if len(ids) != webhookQueueSize+1 {
+		t.Fatalf("sent %d batches, want %d", len(ids), webhookQueueSize+1)
+	}
+	if errs.Len() != 0 {
+		t.Fatalf("errors: %s", errs.String())
+	}
+}
+
+func TestWebhookQueueStopFlushesPending(t *testing.T) {
+	var mu sync.Mutex
+	sent := 0
+	// A slow receiver keeps batches queued when stop is called
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		sent++
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	var errs bytes.Buffer
+	queue := startWebhooks(notifiers{testWebhook(t, WebhookConfig{URL: server.URL})}, &errs)
+	for range 5 {
+		if !queue.send([]watchEvent{webhookEvent(), webhookEvent()}) {
+			t.Fatal("a batch was dropped")
+		}
+	}
+	queue.stop()
+	mu.Lock()
+	defer mu.Unlock()
+	if sent != 10 {
+		t.Fatalf("sent %d webhooks before stop returned, want 10", sent)
+	}
+	if errs.Len() != 0 {
+		t.Fatalf("errors: %s", errs.String())
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/workitemtracking"
+)
+
+// workItem is a Boards work item linked to a pull request
+type workItem struct {
+	id         int
+	kind       string
+	title      string
+	state      string
+	assignedTo string
+	url        string
+}
+
+// workItemFields are the only fields fetched for linked work items
+var workItemFields = []string{"System.WorkItemType", "System.Title", "System.State", "System.AssignedTo"}
+
+// ListWorkItems returns the work items linked to the pull request, ordered by id
+func (s *Session) ListWorkItems(pr PullRequestInfo) ([]workItem, error) {
+	refs, err := s.gitClient.GetPullRequestWorkItemRefs(s.ctx, git.GetPullRequestWorkItemRefsArgs{
+		RepositoryId:  &pr.repositoryID,
+		PullRequestId: &pr.id,
+		Project:       &s.project,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var ids []int
+	if refs != nil {
+		for _, ref := range *refs {
+			if id, err := strconv.Atoi(derefString(ref.Id)); err == nil {
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	client, err := s.workItemTracking()
+	if err != nil {
+		return nil, err
+	}
+	// Work items that were deleted or are hidden from the user are left out
+	omit := workitemtracking.WorkItemErrorPolicyValues.Omit
+	items, err := client.GetWorkItems(s.ctx, workitemtracking.GetWorkItemsArgs{
+		Ids:         &ids,
+		Project:     &s.project,
+		Fields:      &workItemFields,
+		ErrorPolicy: &omit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var infos []workItem
+	if items != nil {
+		for _, item := range *items {
+			if item.Id == nil {
+				continue
+			}
+			infos = append(infos, s.createWorkItem(item))
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].id < infos[j].id })
+	return infos, nil
+}
+
+// createWorkItem converts a work item and its loosely typed fields
+func (s *Session) createWorkItem(item workitemtracking.WorkItem) workItem {
+	var fields map[string]interface{}
+	if item.Fields != nil {
+		fields = *item.Fields
+	}
+	str := func(name string) string {
+		v, _ := fields[name].(string)
+		return v
+	}
+	info := workItem{
+		id:    derefInt(item.Id),
+		kind:  str("System.WorkItemType"),
+		title: str("System.Title"),
+		state: str("System.State"),
+		url:   fmt.Sprintf("https://dev.azure.com/%s/%s/_workitems/edit/%d", url.PathEscape(s.organization), url.PathEscape(s.project), derefInt(item.Id)),
+	}
+	// Identity fields are objects, or a "Name <email>" string on older servers
+	switch assigned := fields["System.AssignedTo"].(type) {
+	case map[string]interface{}:
+		info.assignedTo, _ = assigned["displayName"].(string)
+	case string:
+		info.assignedTo = strings.TrimSpace(strings.Split(assigned, "<")[0])
+	}
+	return info
+}
+
+// prWorkItems holds the linked work items of one PR once they have been loaded
+type prWorkItems struct {
+	items []workItem
+	err   error
+}
+
+type workItemsLoadedMsg struct {
+	prID  int
+	items []workItem
+	err   error
+}
+
+// loadWorkItemsCmd fetches the linked work items of every PR
+func loadWorkItemsCmd(session *Session, prs []PullRequestInfo) tea.Cmd {
+	if session == nil {
+		return nil
+	}
+	cmds := make([]tea.Cmd, 0, len(prs))
+	for _, pr := range prs {
+		cmds = append(cmds, func() tea.Msg {
+			checksLimit <- struct{}{}
+			defer func() { <-checksLimit }()
+			items, err := session.ListWorkItems(pr)
+			return workItemsLoadedMsg{prID: pr.id, items: items, err: err}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// unlinked reports whether the PR is known to have no linked work items
+func (m tuiModel) unlinked(prID int) bool {
+	items, ok := m.workItems[prID]
+	return ok && items.err == nil && len(items.items) == 0
+}
+
+// workItemsState is the work item picker of one PR
+type workItemsState struct {
+	pr       PullRequestInfo
+	selected int
+}
+
+// openWorkItems opens the only work item linked to the selected PR in the browser,
+// or lets the user pick one when there are several
+func (m tuiModel) openWorkItems() (tuiModel, tea.Cmd) {
+	pr, ok := m.selectedPR()
+	if !ok {
+		return m, nil
+	}
+	items := m.workItems[pr.id].items
+	switch len(items) {
+	case 0:
+		return m, func() tea.Msg {
+			return statusMsg{err: fmt.Errorf("!%d has no linked work items", pr.id)}
+		}
+	case 1:
+		return m, openBrowserCmd(items[0].url)
+	}
+	m.view = viewWorkItems
+	m.workItemPicker = workItemsState{pr: pr}
+	return m, nil
+}
+
+// updateWorkItems handles keys in the work item picker
+func (m tuiModel) updateWorkItems(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	items := m.workItems[m.workItemPicker.pr.id].items
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		m.workItemPicker.selected = max(0, m.workItemPicker.selected-1)
+	case key.Matches(msg, m.keys.Down):
+		m.workItemPicker.selected = max(0, min(m.workItemPicker.selected+1, len(items)-1))
+	case key.Matches(msg, m.keys.Details, m.keys.OpenBrowser, m.keys.WorkItems):
+		if m.workItemPicker.selected < len(items) {
+			return m, openBrowserCmd(items[m.workItemPicker.selected].url)
+		}
+	case key.Matches(msg, m.keys.Help):
+		m.showHelp = true
+	case key.Matches(msg, m.keys.Quit):
+		m.view = viewPRs
+	}
+	return m, nil
+}
+
+// workItemRow formats one work item as a table row
+func workItemRow(item workItem, width int) string {
+	head := fmt.Sprintf("%-7d %-12s %-10s ", item.id, truncate(item.kind, 12), truncate(item.state, 10))
+	rest := max(0, width-lipgloss.Width(head))
+	assigned := ""
+	if item.assignedTo != "" && rest > 30 {
+		assigned = " (" + truncate(item.assignedTo, 20) + ")"
+	}
+	return head + truncate(item.title, rest-len([]rune(assigned))) + assigned
+}
+
+// renderWorkItems renders the linked work items of a PR for the detail pane
+func (m tuiModel) renderWorkItems(prID, width int) []string {
+	lines := []string{"Work items:"}
+	items, ok := m.workItems[prID]
+	switch {
+	case !ok:
+		return append(lines, sepStyle.Render("Loading..."))
+	case items.err != nil:
+		return append(lines, errorStyle.Render(truncate(items.err.Error(), width)))
+	case len(items.items) == 0:
+		return append(lines, sepStyle.Render("No linked work items."))
+	}
+	for _, item := range items.items {
+		lines = append(lines, workItemRow(item, width))
+	}
+	return lines
+}
+
+// renderWorkItemPicker renders the work items of a PR for choosing one to open
+func (m tuiModel) renderWorkItemPicker(width, height int) (string, paneLayout) {
+	frameWidth, frameHeight := boxStyle.GetFrameSize()
+	usableWidth := width - frameWidth
+	items := m.workItems[m.workItemPicker.pr.id].items
+	header := titleStyle.UnsetHeight().UnsetMarginBottom().Width(usableWidth).Render(fmt.Sprintf("Work items linked to !%d", m.workItemPicker.pr.id))
+	lines := []string{header, ""}
+	visible := max(1, height-frameHeight-len(lines))
+	first := 0
+	if m.workItemPicker.selected >= visible {
+		first = m.workItemPicker.selected - visible + 1
+	}
+	last := min(len(items), first+visible)
+	pane := paneLayout{
+		first: first,
+		content: rect{
+			x: boxStyle.GetMarginLeft() + boxStyle.GetBorderLeftSize() + boxStyle.GetPaddingLeft(),
+			y: boxStyle.GetBorderTopSize() + boxStyle.GetPaddingTop() + len(lines),
+			w: usableWidth,
+			h: last - first,
+		},
+	}
+	for i := first; i < last; i++ {
+		cursor := " "
+		if i == m.workItemPicker.selected {
+			cursor = ">"
+		}
+		line := cursor + " " + workItemRow(items[i], usableWidth-2)
+		if i == m.workItemPicker.selected {
+			line = selectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	box := boxStyle.Width(width - boxStyle.GetHorizontalMargins() - boxStyle.GetHorizontalBorderSize()).Render(strings.Join(lines, "\n"))
+	pane.area = rect{w: lipgloss.Width(box), h: lipgloss.Height(box)}
+	return box, pane
+}